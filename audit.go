@@ -0,0 +1,224 @@
+package domoapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+//ActivityLogEntry is an event of the Domo activity log. The token needs the "audit" scope.
+type ActivityLogEntry struct {
+	UserID               string    `json:"userId,omitempty"`
+	UserName             string    `json:"userName,omitempty"`
+	UserType             string    `json:"userType,omitempty"`
+	ActorID              string    `json:"actorId,omitempty"`
+	ActorName            string    `json:"actorName,omitempty"`
+	ActorType            string    `json:"actorType,omitempty"`
+	ObjectID             string    `json:"objectId,omitempty"`
+	ObjectName           string    `json:"objectName,omitempty"`
+	ObjectType           string    `json:"objectType,omitempty"`
+	ActionType           string    `json:"actionType,omitempty"`
+	EventText            string    `json:"eventText,omitempty"`
+	AdditionalComment    string    `json:"additionalComment,omitempty"`
+	IPAddress            string    `json:"ipAddress,omitempty"`
+	BrowserDetails       string    `json:"browserDetails,omitempty"`
+	AuthenticationMethod string    `json:"authenticationMethod,omitempty"`
+	ClientID             string    `json:"clientId,omitempty"`
+	Time                 time.Time `json:"time"`
+}
+
+//UnmarshalJSON decodes an activity log event, Domo sends IDs either as numbers or strings and the time in epoch milliseconds
+func (e *ActivityLogEntry) UnmarshalJSON(data []byte) error {
+	type entry ActivityLogEntry
+	var raw struct {
+		*entry
+		UserID   json.RawMessage `json:"userId"`
+		ActorID  json.RawMessage `json:"actorId"`
+		ObjectID json.RawMessage `json:"objectId"`
+		Time     int64           `json:"time"`
+	}
+	raw.entry = (*entry)(e)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	e.UserID = rawID(raw.UserID)
+	e.ActorID = rawID(raw.ActorID)
+	e.ObjectID = rawID(raw.ObjectID)
+	e.Time = time.Unix(0, raw.Time*int64(time.Millisecond)).UTC()
+	return nil
+}
+
+//rawID returns a json string or number as a string, null as ""
+func rawID(raw json.RawMessage) string {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+//maxActivityLogPageSize is the largest number of events Domo returns per page
+const maxActivityLogPageSize = 1000
+
+//DefaultActivityLogWindow is the time window IterateActivityLog walks when none is set
+const DefaultActivityLogWindow = 24 * time.Hour
+
+//ActivityLogQuery selects a page of activity log events. Start is required, End defaults to now.
+type ActivityLogQuery struct {
+	UserID int64
+	Start  time.Time
+	End    time.Time
+	Limit  int
+	Offset int
+}
+
+func millis(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+//GetActivityLog gets a page of the activity log, limit is at most 1000
+func (d *DomoAPI) GetActivityLog(ctx context.Context, q ActivityLogQuery) ([]ActivityLogEntry, error) {
+	if q.Start.IsZero() {
+		return nil, fmt.Errorf("error: activity log queries need a start time")
+	}
+	params := url.Values{}
+	params.Set("start", millis(q.Start))
+	if !q.End.IsZero() {
+		params.Set("end", millis(q.End))
+	}
+	if q.UserID != 0 {
+		params.Set("user", strconv.FormatInt(q.UserID, 10))
+	}
+	if q.Limit > 0 {
+		params.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Offset > 0 {
+		params.Set("offset", strconv.Itoa(q.Offset))
+	}
+	req, err := d.newRequest(ctx, http.MethodGet, "/v1/audit?"+params.Encode(), nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ActivityLogEntry
+	if err := d.doJSON(req, &entries, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+//ActivityLogOptions selects the events walked by IterateActivityLog
+type ActivityLogOptions struct {
+	//UserID only keeps the events of a user
+	UserID int64
+	//Start is required, End defaults to the time the iterator is created
+	Start time.Time
+	End   time.Time
+	//Window is the span of time requested at once, DefaultActivityLogWindow when zero
+	Window time.Duration
+	//PageSize is the number of events per request, at most and by default 1000
+	PageSize int
+}
+
+//ActivityLogIterator walks the activity log from Start to End, one time window and page at a time,
+//so that long periods are exported without holding them in memory.
+//
+//	it := d.IterateActivityLog(ctx, domoapi.ActivityLogOptions{Start: time.Now().Add(-7 * 24 * time.Hour)})
+//	for it.Next() {
+//		e := it.Entry()
+//		...
+//	}
+//	err := it.Err()
+type ActivityLogIterator struct {
+	d    *DomoAPI
+	ctx  context.Context
+	opts ActivityLogOptions
+
+	page   []ActivityLogEntry
+	from   time.Time //start of the current window
+	offset int       //offset of the next page in the current window
+	cur    ActivityLogEntry
+	err    error
+}
+
+//IterateActivityLog returns an iterator over the activity log events selected by opts
+func (d *DomoAPI) IterateActivityLog(ctx context.Context, opts ActivityLogOptions) *ActivityLogIterator {
+	if opts.End.IsZero() {
+		opts.End = time.Now()
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultActivityLogWindow
+	}
+	if opts.PageSize <= 0 || opts.PageSize > maxActivityLogPageSize {
+		opts.PageSize = maxActivityLogPageSize
+	}
+	it := &ActivityLogIterator{d: d, ctx: ctx, opts: opts, from: opts.Start}
+	if opts.Start.IsZero() {
+		it.err = fmt.Errorf("error: activity log queries need a start time")
+	}
+	return it
+}
+
+//Next advances to the next event, it returns false at End or on error
+func (it *ActivityLogIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.err != nil || !it.from.Before(it.opts.End) {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+	it.cur = it.page[0]
+	it.page = it.page[1:]
+	return true
+}
+
+//fetch reads the next page of the current window and moves to the next window after a short page
+func (it *ActivityLogIterator) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err
+	}
+	to := it.from.Add(it.opts.Window)
+	if to.After(it.opts.End) {
+		to = it.opts.End
+	}
+	//the end of a window is inclusive, stop a millisecond before the next window starts
+	page, err := it.d.GetActivityLog(it.ctx, ActivityLogQuery{
+		UserID: it.opts.UserID,
+		Start:  it.from,
+		End:    to.Add(-time.Millisecond),
+		Limit:  it.opts.PageSize,
+		Offset: it.offset,
+	})
+	if err != nil {
+		return err
+	}
+	it.page = page
+	if len(page) < it.opts.PageSize {
+		it.from = to
+		it.offset = 0
+	} else {
+		it.offset += len(page)
+	}
+	return nil
+}
+
+//Entry returns the current event
+func (it *ActivityLogIterator) Entry() ActivityLogEntry {
+	return it.cur
+}
+
+//Err returns the error which stopped the iteration, if any
+func (it *ActivityLogIterator) Err() error {
+	return it.err
+}
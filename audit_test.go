@@ -0,0 +1,168 @@
+package domoapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+const activityLogJSON = `[{
+	"userName": "Leonhard Euler",
+	"userId": "27",
+	"userType": "USER",
+	"actorId": 0,
+	"objectName": "Parties",
+	"objectId": 384540563,
+	"objectType": "PAGE",
+	"actionType": "VIEWED",
+	"eventText": "Viewed page",
+	"time": 1590969600123
+}]`
+
+func TestActivityLogEntry_UnmarshalJSON(t *testing.T) {
+	var got []ActivityLogEntry
+	if err := json.Unmarshal([]byte(activityLogJSON), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	want := []ActivityLogEntry{{
+		UserID:     "27",
+		UserName:   "Leonhard Euler",
+		UserType:   "USER",
+		ActorID:    "0",
+		ObjectID:   "384540563",
+		ObjectName: "Parties",
+		ObjectType: "PAGE",
+		ActionType: "VIEWED",
+		EventText:  "Viewed page",
+		Time:       time.Date(2020, 6, 1, 0, 0, 0, 123*int(time.Millisecond), time.UTC),
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("json.Unmarshal() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDomoAPI_GetActivityLog(t *testing.T) {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		query    ActivityLogQuery
+		wantPath string
+		wantErr  bool
+	}{
+		{
+			name:     "all filters",
+			query:    ActivityLogQuery{UserID: 27, Start: start, End: start.Add(time.Hour), Limit: 100, Offset: 200},
+			wantPath: "/v1/audit?end=1590973200000&limit=100&offset=200&start=1590969600000&user=27",
+		},
+		{
+			name:     "start only",
+			query:    ActivityLogQuery{Start: start},
+			wantPath: "/v1/audit?start=1590969600000",
+		},
+		{
+			name:    "missing start",
+			query:   ActivityLogQuery{UserID: 27},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rmock := mocks.NewMockRequestHandlerService(ctrl)
+			rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				if req.Method != http.MethodGet || req.URL.RequestURI() != tt.wantPath {
+					t.Errorf("request = %v %v, want GET %v", req.Method, req.URL.RequestURI(), tt.wantPath)
+				}
+				return getMockResponse(activityLogJSON, 200), nil
+			}).AnyTimes()
+			domoAPI := &DomoAPI{
+				requestHandlerService: rmock,
+				tokens:                &tokenSource{token: &sampleToken},
+			}
+
+			got, err := domoAPI.GetActivityLog(context.Background(), tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DomoAPI.GetActivityLog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (len(got) != 1 || got[0].UserID != "27") {
+				t.Errorf("DomoAPI.GetActivityLog() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestDomoAPI_IterateActivityLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	//window start -> pages of events, a full page of 2 is followed by another request
+	pages := map[string][]string{
+		millis(start):                     {`[{"eventText": "a"}, {"eventText": "b"}]`, `[{"eventText": "c"}]`},
+		millis(start.Add(24 * time.Hour)): {`[]`},
+		millis(start.Add(48 * time.Hour)): {`[{"eventText": "d"}]`},
+	}
+	var calls []string
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		calls = append(calls, fmt.Sprintf("%s-%s@%s", q.Get("start"), q.Get("end"), q.Get("offset")))
+		if q.Get("user") != "27" || q.Get("limit") != "2" {
+			t.Errorf("request = %v", req.URL)
+		}
+		left := pages[q.Get("start")]
+		if len(left) == 0 {
+			t.Fatalf("unexpected request %v", req.URL)
+		}
+		pages[q.Get("start")] = left[1:]
+		return getMockResponse(left[0], 200), nil
+	}).AnyTimes()
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	it := domoAPI.IterateActivityLog(context.Background(), ActivityLogOptions{
+		UserID:   27,
+		Start:    start,
+		End:      start.Add(60 * time.Hour),
+		PageSize: 2,
+	})
+	var got []string
+	for it.Next() {
+		got = append(got, it.Entry().EventText)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("ActivityLogIterator.Err() = %v", err)
+	}
+	if strings.Join(got, "") != "abcd" {
+		t.Errorf("events = %v, want [a b c d]", got)
+	}
+	wantCalls := []string{
+		"1590969600000-1591055999999@",
+		"1590969600000-1591055999999@2",
+		"1591056000000-1591142399999@",
+		"1591142400000-1591185599999@",
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", calls, wantCalls)
+	}
+}
+
+func TestDomoAPI_IterateActivityLog_missingStart(t *testing.T) {
+	domoAPI := &DomoAPI{tokens: &tokenSource{token: &sampleToken}}
+	it := domoAPI.IterateActivityLog(context.Background(), ActivityLogOptions{UserID: 27})
+	if it.Next() || it.Err() == nil {
+		t.Errorf("IterateActivityLog() without a start should fail")
+	}
+}
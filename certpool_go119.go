@@ -0,0 +1,11 @@
+//go:build go1.19
+// +build go1.19
+
+package domoapi
+
+import "crypto/x509"
+
+//cloneCertPool copies pool so that certificates can be added without changing the caller's pool
+func cloneCertPool(pool *x509.CertPool) (*x509.CertPool, error) {
+	return pool.Clone(), nil
+}
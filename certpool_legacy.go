@@ -0,0 +1,15 @@
+//go:build !go1.19
+// +build !go1.19
+
+package domoapi
+
+import (
+	"crypto/x509"
+	"fmt"
+)
+
+//cloneCertPool fails before go1.19, which has no way to copy a pool: adding the ca file
+//would change the caller's pool
+func cloneCertPool(pool *x509.CertPool) (*x509.CertPool, error) {
+	return nil, fmt.Errorf("error: cannot add the ca file to TLSConfig.RootCAs before go1.19, append it to the pool instead of setting CAFile")
+}
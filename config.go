@@ -0,0 +1,73 @@
+package domoapi
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+//Config holds the settings used to talk to a single domo instance
+type Config struct {
+	//APIURL is the domo api url, e.g. https://api.domo.com
+	APIURL string
+	//ClientID is the domo client id used to create access tokens
+	ClientID string
+	//ClientSecret is the domo client secret used to create access tokens
+	ClientSecret string
+	//ProxyURL is the proxy used to access domo from a proxied environment. Optional
+	ProxyURL string
+	//AuthScope is the comma separated list of token scopes. Defaults to "data"
+	AuthScope string
+
+	//Timeout is the http client timeout. Defaults to DefaultTimeout
+	Timeout time.Duration
+	//CAFile is a PEM encoded CA bundle trusted in addition to the system roots, e.g. for a corporate proxy
+	CAFile string
+	//TLSConfig is the tls configuration of the http transport. CAFile is added to a copy of its RootCAs,
+	//which needs go1.19 or later when RootCAs is set
+	TLSConfig *tls.Config
+	//MaxIdleConns limits the idle connections across all hosts. Zero keeps the net/http default
+	MaxIdleConns int
+	//MaxIdleConnsPerHost limits the idle connections kept for domo. Defaults to DefaultMaxIdleConnsPerHost
+	MaxIdleConnsPerHost int
+	//IdleConnTimeout is how long an idle connection is kept. Zero keeps the net/http default
+	IdleConnTimeout time.Duration
+
+	//RequestHandler replaces the request handler built from the http settings above
+	RequestHandler RequestHandlerService
+	//Retry is the retry policy wrapped around the request handler, e.g. &ExponentialBackoff{}. Nil disables retries
+	Retry RetryPolicy
+}
+
+//ConfigFromEnv loads the configuration from the DOMO_* environment variables
+func ConfigFromEnv() Config {
+	return Config{
+		APIURL:       os.Getenv("DOMO_API_URL"),
+		ClientID:     os.Getenv("DOMO_CLIENT_ID"),
+		ClientSecret: os.Getenv("DOMO_CLIENT_SECRET"),
+		ProxyURL:     os.Getenv("DOMO_PROXY_URL"),
+		AuthScope:    os.Getenv("DOMO_AUTH_SCOPE"),
+		CAFile:       os.Getenv("DOMO_CA_FILE"),
+	}
+}
+
+func (c Config) validate() error {
+	if c.APIURL == "" {
+		return fmt.Errorf("error: missing api url")
+	}
+	return nil
+}
+
+func (c Config) scope() string {
+	if c.AuthScope == "" {
+		return "data"
+	}
+	return c.AuthScope
+}
+
+//endpoint joins the configured api url with the given path
+func (c Config) endpoint(path string) string {
+	return strings.TrimRight(c.APIURL, "/") + path
+}
@@ -0,0 +1,117 @@
+package domoapi
+
+import (
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		"DOMO_API_URL":       "https://api.domo.example.com",
+		"DOMO_CLIENT_ID":     "dummy_id",
+		"DOMO_CLIENT_SECRET": "dummy_secret",
+		"DOMO_PROXY_URL":     "https://proxy_dummy.example.com:8080",
+		"DOMO_AUTH_SCOPE":    "data,user",
+		"DOMO_CA_FILE":       "/etc/ssl/corporate.pem",
+	}
+	for k, v := range env {
+		old, ok := os.LookupEnv(k)
+		os.Setenv(k, v)
+		defer func(k, old string, ok bool) {
+			if ok {
+				os.Setenv(k, old)
+			} else {
+				os.Unsetenv(k)
+			}
+		}(k, old, ok)
+	}
+
+	want := Config{
+		APIURL:       "https://api.domo.example.com",
+		ClientID:     "dummy_id",
+		ClientSecret: "dummy_secret",
+		ProxyURL:     "https://proxy_dummy.example.com:8080",
+		AuthScope:    "data,user",
+		CAFile:       "/etc/ssl/corporate.pem",
+	}
+	if got := ConfigFromEnv(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigFromEnv() = %v, want %v", got, want)
+	}
+}
+
+func TestNewDomoAPIWithConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{
+			name: "valid config",
+			cfg: Config{
+				APIURL:       "https://api.domo.example.com",
+				ClientID:     "dummy_id",
+				ClientSecret: "dummy_secret",
+			},
+		},
+		{
+			name:    "missing api url",
+			cfg:     Config{ClientID: "dummy_id"},
+			wantErr: true,
+		},
+		{
+			name: "invalid proxy url",
+			cfg: Config{
+				APIURL:   "https://api.domo.example.com",
+				ProxyURL: "proxy_dummy.example.com:8080",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewDomoAPIWithConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewDomoAPIWithConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got.config, tt.cfg) {
+				t.Errorf("NewDomoAPIWithConfig() config = %v, want %v", got.config, tt.cfg)
+			}
+		})
+	}
+}
+
+func TestDomoAPI_CreateAccessToken_UsesConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		wantURL := "https://instance-a.example.com/oauth/token?grant_type=client_credentials&scope=data,user"
+		if req.URL.String() != wantURL {
+			t.Errorf("request url = %v, want %v", req.URL.String(), wantURL)
+		}
+		id, secret, ok := req.BasicAuth()
+		if !ok || id != "client_a" || secret != "secret_a" {
+			t.Errorf("basic auth = %v:%v, want client_a:secret_a", id, secret)
+		}
+		return getMockResponse(tokenAPIRespJSON, 200), nil
+	})
+	domoAPI := &DomoAPI{
+		config: Config{
+			APIURL:       "https://instance-a.example.com/",
+			ClientID:     "client_a",
+			ClientSecret: "secret_a",
+			AuthScope:    "data,user",
+		},
+		requestHandlerService: rmock,
+	}
+	if _, err := domoAPI.CreateAccessToken(); err != nil {
+		t.Errorf("DomoAPI.CreateAccessToken() error = %v", err)
+	}
+}
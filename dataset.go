@@ -1,36 +1,64 @@
 package domoapi
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/url"
-	"os"
+	"strings"
 	"time"
 )
 
+//RequestHandlerService sends http requests to domo
 type RequestHandlerService interface {
 	Handler(req *http.Request) (*http.Response, error)
 }
 
-type RequestHandler struct{}
+//ContextRequestHandlerService is a RequestHandlerService that takes the request context explicitly.
+//DomoAPI prefers HandlerContext when the configured handler implements it.
+type ContextRequestHandlerService interface {
+	RequestHandlerService
+	HandlerContext(ctx context.Context, req *http.Request) (*http.Response, error)
+}
 
 type DomoAPI struct {
+	config                Config
 	requestHandlerService RequestHandlerService
+	tokens                *tokenSource
+}
+
+//NewDomoAPI creates DomoAPI configured from the DOMO_* environment variables
+func NewDomoAPI() (*DomoAPI, error) {
+	return newDomoAPI(ConfigFromEnv())
 }
 
-func NewDomoAPI() *DomoAPI {
-	return &DomoAPI{
-		requestHandlerService: &RequestHandler{},
+//NewDomoAPIWithConfig creates DomoAPI for the domo instance described by cfg
+func NewDomoAPIWithConfig(cfg Config) (*DomoAPI, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
 	}
+	return newDomoAPI(cfg)
 }
 
-type Token struct {
-	AccessToken string `json:"access_token,omitempty"`
-	ExpiresIn   int    `json:"expires_in,omitempty"`
-	ExpiresAt   time.Time
+func newDomoAPI(cfg Config) (*DomoAPI, error) {
+	handler := cfg.RequestHandler
+	if handler == nil {
+		h, err := NewRequestHandler(cfg)
+		if err != nil {
+			return nil, err
+		}
+		handler = h
+	}
+	if cfg.Retry != nil {
+		handler = NewRetryHandler(handler, cfg.Retry)
+	}
+	d := &DomoAPI{
+		config:                cfg,
+		requestHandlerService: handler,
+	}
+	d.tokens = newTokenSource(d.CreateAccessTokenContext)
+	return d, nil
 }
 
 //DomoDataset
@@ -63,190 +91,180 @@ type Column struct {
 	Name string `json:"name,omitempty"`
 }
 
-//GetDataByDatasetID fetch data given domo's datasetID. Use header=true to include header in the response
-func (d *DomoAPI) GetDataByDatasetID(token string, datasetID string, header bool) (string, error) {
-	includeHeader := ""
-	if header {
-		includeHeader = "?includeHeader=true"
-	}
-	apiURL := os.Getenv("DOMO_API_URL") + "/v1/datasets/" + datasetID + "/data" + includeHeader
-	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Add("Content-Type", "text/csv")
-	req.Header.Add("Authorization", "bearer "+token)
+//Domo column types
+const (
+	ColumnTypeString   = "STRING"
+	ColumnTypeLong     = "LONG"
+	ColumnTypeDouble   = "DOUBLE"
+	ColumnTypeDecimal  = "DECIMAL"
+	ColumnTypeDate     = "DATE"
+	ColumnTypeDateTime = "DATETIME"
+)
 
-	resp, err := d.requestHandlerService.Handler(req)
+//GetDataByDatasetID fetch data given domo's datasetID. Use header=true to include header in the response.
+//The whole export is held in memory, use ExportDataset to stream large datasets.
+func (d *DomoAPI) GetDataByDatasetID(datasetID string, header bool) (string, error) {
+	return d.GetDataByDatasetIDContext(context.Background(), datasetID, header)
+}
+
+//GetDataByDatasetIDContext is GetDataByDatasetID with a context
+func (d *DomoAPI) GetDataByDatasetIDContext(ctx context.Context, datasetID string, header bool) (string, error) {
+	r, err := d.ExportDataset(ctx, datasetID, ExportOptions{IncludeHeader: header})
 	if err != nil {
 		return "", err
 	}
-	body, err := ioutil.ReadAll(resp.Body)
+	defer r.Close()
+
+	body, err := ioutil.ReadAll(r)
 	if err != nil {
 		return "", err
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("Api request failed : %v", string(body))
-	}
-
 	return string(body), nil
 }
 
 //GetDatasetIDByName get domo datasetID using domo dataset name
-func (d *DomoAPI) GetDatasetIDByName(datasetName string, token string) ([]string, error) {
+func (d *DomoAPI) GetDatasetIDByName(datasetName string) ([]string, error) {
+	return d.GetDatasetIDByNameContext(context.Background(), datasetName)
+}
 
-	var datasetIDs []string
-	datasets, err := d.ListDatasets(token)
-	if err != nil {
-		return nil, err
-	}
-	for _, d := range datasets {
-		if d.Name == datasetName {
-			datasetIDs = append(datasetIDs, d.ID)
-		}
-	}
-	if len(datasetIDs) > 0 {
-		return datasetIDs, nil
-	}
-	return nil, nil
+//GetDatasetIDByNameContext is GetDatasetIDByName with a context.
+//Use FindDatasetIDs for case-insensitive or prefix matching and to stop at the first match.
+func (d *DomoAPI) GetDatasetIDByNameContext(ctx context.Context, datasetName string) ([]string, error) {
+	return d.FindDatasetIDs(ctx, datasetName, FindDatasetOptions{})
 }
 
 //ListDatasets list all domo datasets in the belonging domo instance
-func (d *DomoAPI) ListDatasets(token string) ([]DomoDataset, error) {
-	var dataSets []DomoDataset
-
-	var tmpSets []DomoDataset
-	limit := 50
-	counter := 1
-
-	for counter >= 1 {
-		strOffset := fmt.Sprintf("&offset=%d", (counter-1)*limit)
-		strLimit := fmt.Sprintf("&limit=%d", limit)
-		apiURL := os.Getenv("DOMO_API_URL") + "/v1/datasets?sort=name" + strLimit + strOffset
-		req, err := http.NewRequest(http.MethodGet, apiURL, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		req.Header.Add("Content-Type", "application/json")
-		req.Header.Add("Authorization", "bearer "+token)
+func (d *DomoAPI) ListDatasets() ([]DomoDataset, error) {
+	return d.ListDatasetsContext(context.Background())
+}
 
-		resp, err := d.requestHandlerService.Handler(req)
-		if err != nil {
-			return nil, err
-		}
+//ListDatasetsContext is ListDatasets with a context. Cancelling ctx stops the pagination between pages.
+//Use ListDatasetsWithOptions or IterateDatasets to filter, sort or page the listing.
+func (d *DomoAPI) ListDatasetsContext(ctx context.Context) ([]DomoDataset, error) {
+	return d.ListDatasetsWithOptions(ctx, ListDatasetsOptions{})
+}
 
-		if resp.StatusCode != http.StatusOK {
+//AddDataToDataset adds data to the given dataset. Use replace=true to reset dataset's data with the given data.
+//Use ImportDataset to upload large data from an io.Reader.
+func (d *DomoAPI) AddDataToDataset(datasetID string, data string, replace bool) error {
+	return d.AddDataToDatasetContext(context.Background(), datasetID, data, replace)
+}
 
-			return nil, fmt.Errorf("Domo api resoonseded with erorr: %d", resp.StatusCode)
-		}
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		if err := json.Unmarshal(body, &tmpSets); err != nil {
-			return nil, fmt.Errorf("Cannot parse json : %v", err)
-		}
-		dataSets = append(dataSets, tmpSets...)
-		counter++
-		if len(tmpSets) == 0 {
-			break
-		}
-	}
+//AddDataToDatasetContext is AddDataToDataset with a context
+func (d *DomoAPI) AddDataToDatasetContext(ctx context.Context, datasetID string, data string, replace bool) error {
+	return d.ImportDataset(ctx, datasetID, strings.NewReader(data), ImportOptions{Replace: replace})
+}
 
-	return dataSets, nil
+//CreateDataset create dataset on domo instance
+func (d *DomoAPI) CreateDataset(dds DomoDataset) (*DomoDataset, error) {
+	return d.CreateDatasetContext(context.Background(), dds)
 }
 
-//AddDataToDataset adds data to the given dataset. Use replace=true to reset dataset's data with the given data.
-func (d *DomoAPI) AddDataToDataset(datasetID string, data string, replace bool, token string) error {
-	if datasetID == "" {
-		return fmt.Errorf(" error : issing datasetID")
-	}
-	method := "APPEND"
-	if replace {
-		method = "REPLACE"
-	}
-	apiURL := os.Getenv("DOMO_API_URL") + "/v1/datasets/" + datasetID + "/data?updateMethod=" + method
-	req, err := http.NewRequest(http.MethodPut, apiURL, bytes.NewBuffer([]byte(data)))
+//CreateDatasetContext is CreateDataset with a context
+func (d *DomoAPI) CreateDatasetContext(ctx context.Context, dds DomoDataset) (*DomoDataset, error) {
+	req, err := d.newJSONRequest(ctx, http.MethodPost, "/v1/datasets", dds)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req.Header.Add("Content-Type", "text/csv")
-	req.Header.Add("Authorization", "bearer "+token)
 
-	resp, err := d.requestHandlerService.Handler(req)
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("Domo api resonseded with erorr: %d \n URL: %s", resp.StatusCode, apiURL)
+	var s *DomoDataset
+	if err := d.doJSON(req, &s, http.StatusCreated); err != nil {
+		return nil, err
 	}
-	return err
+	return s, nil
 }
 
-//CreateDataset create dataset on domo instance
-func (d *DomoAPI) CreateDataset(dds DomoDataset, token string) (*DomoDataset, error) {
-	apiURL := os.Getenv("DOMO_API_URL") + "/v1/datasets"
+//GetDataset fetches the metadata of a single dataset including its schema and owner
+func (d *DomoAPI) GetDataset(datasetID string) (*DomoDataset, error) {
+	return d.GetDatasetContext(context.Background(), datasetID)
+}
 
-	sDataset, err := json.Marshal(dds)
+//GetDatasetContext is GetDataset with a context
+func (d *DomoAPI) GetDatasetContext(ctx context.Context, datasetID string) (*DomoDataset, error) {
+	if datasetID == "" {
+		return nil, fmt.Errorf("error: missing datasetID")
+	}
+	req, err := d.newRequest(ctx, http.MethodGet, "/v1/datasets/"+datasetID, nil, "application/json")
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewBuffer(sDataset))
-	if err != nil {
+
+	var s *DomoDataset
+	if err := d.doJSON(req, &s, http.StatusOK); err != nil {
 		return nil, err
 	}
+	return s, nil
+}
 
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", "bearer "+token)
+//UpdateDataset changes the name, description and schema of an existing dataset and returns the updated dataset.
+//Empty fields of dds are left unchanged.
+func (d *DomoAPI) UpdateDataset(datasetID string, dds DomoDataset) (*DomoDataset, error) {
+	return d.UpdateDatasetContext(context.Background(), datasetID, dds)
+}
 
-	resp, err := d.requestHandlerService.Handler(req)
+//UpdateDatasetContext is UpdateDataset with a context
+func (d *DomoAPI) UpdateDatasetContext(ctx context.Context, datasetID string, dds DomoDataset) (*DomoDataset, error) {
+	if datasetID == "" {
+		return nil, fmt.Errorf("error: missing datasetID")
+	}
+	//only name, description and schema can be updated
+	update := DomoDataset{
+		Name:        dds.Name,
+		Description: dds.Description,
+		Schema:      dds.Schema,
+	}
+	req, err := d.newJSONRequest(ctx, http.MethodPut, "/v1/datasets/"+datasetID, update)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("Error creating dataset: %s", string(sDataset))
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
 
 	var s *DomoDataset
-
-	if err := json.Unmarshal(body, &s); err != nil {
-		return nil, fmt.Errorf("Error deserializing dataset schema - %v", err)
+	if err := d.doJSON(req, &s, http.StatusOK); err != nil {
+		return nil, err
 	}
+	return s, nil
+}
 
-	return s, err
+//DeleteDataset permanently deletes a dataset and its data
+func (d *DomoAPI) DeleteDataset(datasetID string) error {
+	return d.DeleteDatasetContext(context.Background(), datasetID)
 }
 
-//CreateAccessToken create domo accessToken using key clientKey and clientSecrete in .env file.
-func (d *DomoAPI) CreateAccessToken() (*Token, error) {
-	scopes := os.Getenv("DOMO_AUTH_SCOPE")
-	if scopes == "" {
-		scopes = "data"
+//DeleteDatasetContext is DeleteDataset with a context
+func (d *DomoAPI) DeleteDatasetContext(ctx context.Context, datasetID string) error {
+	if datasetID == "" {
+		return fmt.Errorf("error: missing datasetID")
 	}
-	apiURL := os.Getenv("DOMO_API_URL") + "/oauth/token?grant_type=client_credentials&scope=" + scopes
-	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	req, err := d.newRequest(ctx, http.MethodDelete, "/v1/datasets/"+datasetID, nil, "")
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return d.doJSON(req, nil, http.StatusNoContent)
+}
 
-	clientID := os.Getenv("DOMO_CLIENT_ID")
-	clientSecret := os.Getenv("DOMO_CLIENT_SECRET")
-	req.SetBasicAuth(clientID, clientSecret)
+//CreateAccessToken create domo accessToken using the configured ClientID and ClientSecret.
+func (d *DomoAPI) CreateAccessToken() (*Token, error) {
+	return d.CreateAccessTokenContext(context.Background())
+}
 
-	resp, err := d.requestHandlerService.Handler(req)
+//CreateAccessTokenContext is CreateAccessToken with a context
+func (d *DomoAPI) CreateAccessTokenContext(ctx context.Context) (*Token, error) {
+	req, err := d.newRequest(ctx, http.MethodGet, "/oauth/token?grant_type=client_credentials&scope="+d.config.scope(), nil, "")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	req.SetBasicAuth(d.config.ClientID, d.config.ClientSecret)
 
-	body, err := ioutil.ReadAll(resp.Body)
+	resp, err := d.send(req)
+	resp, err = checkResponse(req, resp, err, http.StatusOK)
 	if err != nil {
-		return nil, fmt.Errorf("Error reading response body request - %v", err)
+		return nil, err
 	}
+	defer drainAndClose(resp.Body)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Expected status 2XX getting oauth access_token, got %d %s - %s", resp.StatusCode, resp.Status, string(body))
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading response body request - %v", err)
 	}
 
 	var token *Token
@@ -261,21 +279,7 @@ func (d *DomoAPI) CreateAccessToken() (*Token, error) {
 	return token, nil
 }
 
-//Handler handles http client request.
-func (r *RequestHandler) Handler(req *http.Request) (*http.Response, error) {
-	var client *http.Client
-	proxyURL := os.Getenv("DOMO_PROXY_URL")
-	if proxyURL != "" {
-		proxy, _ := url.Parse(proxyURL)
-		transport := &http.Transport{Proxy: http.ProxyURL(proxy)}
-		client = &http.Client{
-			Transport: transport,
-			Timeout:   3 * time.Minute,
-		}
-	} else {
-		client = &http.Client{
-			Timeout: 3 * time.Minute,
-		}
-	}
-	return client.Do(req)
+//send passes req to the configured request handler, using HandlerContext when it is available
+func (d *DomoAPI) send(req *http.Request) (*http.Response, error) {
+	return sendWith(d.requestHandlerService, req)
 }
@@ -2,15 +2,20 @@ package domoapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
-	mocks "github.com/rakutentech/go-domo-api/mocks"
 	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
 )
 
 var (
@@ -103,6 +108,41 @@ func getMockResponse(jsonData string, statusCode int) *http.Response {
 		Body:       r,
 	}
 }
+
+//mockedRequest is a request expected by newMockedDomoAPI and the response it gets.
+//body, when set, must be the exact json sent.
+type mockedRequest struct {
+	method     string
+	uri        string
+	body       string
+	response   string
+	statusCode int
+}
+
+//newMockedDomoAPI returns a DomoAPI expecting exactly one request, want, and answering it
+func newMockedDomoAPI(t *testing.T, ctrl *gomock.Controller, want mockedRequest) *DomoAPI {
+	t.Helper()
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		if req.Method != want.method || req.URL.RequestURI() != want.uri {
+			t.Errorf("request = %v %v, want %v %v", req.Method, req.URL.RequestURI(), want.method, want.uri)
+		}
+		if want.body != "" {
+			var body []byte
+			if req.Body != nil {
+				body, _ = ioutil.ReadAll(req.Body)
+			}
+			if string(body) != want.body {
+				t.Errorf("request body = %s, want %s", body, want.body)
+			}
+		}
+		return getMockResponse(want.response, want.statusCode), nil
+	})
+	return &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+}
 func TestDomoAPI_CreateAccessToken(t *testing.T) {
 
 	tests := []struct {
@@ -172,8 +212,7 @@ func TestDomoAPI_CreateAccessToken(t *testing.T) {
 func TestDomoAPI_CreateDataSet(t *testing.T) {
 
 	type args struct {
-		dds   DomoDataset
-		token Token
+		dds DomoDataset
 	}
 	tests := []struct {
 		name    string
@@ -183,10 +222,7 @@ func TestDomoAPI_CreateDataSet(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name: "success and return dataset",
-			args: args{
-				token: sampleToken,
-			},
+			name:    "success and return dataset",
 			wantErr: false,
 			want: &DomoDataset{
 				Name:        "Leonhard Euler Party",
@@ -213,34 +249,31 @@ func TestDomoAPI_CreateDataSet(t *testing.T) {
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(createDatasetOKJson, 201), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
 		{
-			name: "error and return nil",
-			args: args{
-				token: sampleToken,
-			},
+			name:    "error and return nil",
 			wantErr: true,
 			api: func(ctrl *gomock.Controller) *DomoAPI {
 				rmock := mocks.NewMockRequestHandlerService(ctrl)
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 500), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
 		{
-			name: "domo api return empty",
-			args: args{
-				token: sampleToken,
-			},
+			name:    "domo api return empty",
 			wantErr: true,
 			api: func(ctrl *gomock.Controller) *DomoAPI {
 				rmock := mocks.NewMockRequestHandlerService(ctrl)
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(emptyJSON, 200), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
@@ -251,7 +284,7 @@ func TestDomoAPI_CreateDataSet(t *testing.T) {
 			defer ctrl.Finish()
 			domoAPI := tt.api(ctrl)
 
-			got, err := domoAPI.CreateDataset(tt.args.dds, tt.args.token.AccessToken)
+			got, err := domoAPI.CreateDataset(tt.args.dds)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DomoAPI.CreateDataSet() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -280,7 +313,6 @@ func TestDomoAPI_AddDataToDataset(t *testing.T) {
 		datasetID string
 		data      string
 		replace   bool
-		token     Token
 	}
 	tests := []struct {
 		name    string
@@ -291,7 +323,6 @@ func TestDomoAPI_AddDataToDataset(t *testing.T) {
 		{
 			name: "error and return nil",
 			args: args{
-				token:     sampleToken,
 				datasetID: "ds_id001",
 				replace:   false,
 				data:      "1,1,1,1",
@@ -302,13 +333,13 @@ func TestDomoAPI_AddDataToDataset(t *testing.T) {
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 500), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
 		{
 			name: "wrong status code",
 			args: args{
-				token:     sampleToken,
 				datasetID: "ds_id001",
 				replace:   false,
 				data:      "1,1,1,1",
@@ -319,20 +350,19 @@ func TestDomoAPI_AddDataToDataset(t *testing.T) {
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(emptyJSON, 200), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
 		{
-			name: "missing dataset id",
-			args: args{
-				token: sampleToken,
-			},
+			name:    "missing dataset id",
 			wantErr: true,
 			api: func(ctrl *gomock.Controller) *DomoAPI {
 				rmock := mocks.NewMockRequestHandlerService(ctrl)
 
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
@@ -343,7 +373,7 @@ func TestDomoAPI_AddDataToDataset(t *testing.T) {
 			defer ctrl.Finish()
 			domoAPI := tt.api(ctrl)
 
-			if err := domoAPI.AddDataToDataset(tt.args.datasetID, tt.args.data, tt.args.replace, tt.args.token.AccessToken); (err != nil) != tt.wantErr {
+			if err := domoAPI.AddDataToDataset(tt.args.datasetID, tt.args.data, tt.args.replace); (err != nil) != tt.wantErr {
 				t.Errorf("DomoAPI.AddDataToDataset() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -354,28 +384,21 @@ func TestDomoAPI_ListDatasets(t *testing.T) {
 	var ld []DomoDataset
 	_ = json.Unmarshal([]byte(listDatasetsJSON), &ld)
 
-	type args struct {
-		token Token
-	}
 	tests := []struct {
 		name    string
-		args    args
 		want    []DomoDataset
 		wantErr bool
 		api     func(ctrl *gomock.Controller) *DomoAPI
 	}{
 		{
 			name: "success and get list of datasets",
-			args: args{
-				token: sampleToken,
-			},
 			want: ld,
 			api: func(ctrl *gomock.Controller) *DomoAPI {
 				rmock := mocks.NewMockRequestHandlerService(ctrl)
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(listDatasetsJSON, 200), nil)
-				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse("[]", 200), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
@@ -386,21 +409,20 @@ func TestDomoAPI_ListDatasets(t *testing.T) {
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 500), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 			wantErr: true,
 		},
 		{
-			name: "domo api return empty",
-			args: args{
-				token: sampleToken,
-			},
+			name:    "domo api return empty",
 			wantErr: true,
 			api: func(ctrl *gomock.Controller) *DomoAPI {
 				rmock := mocks.NewMockRequestHandlerService(ctrl)
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(emptyJSON, 200), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
@@ -410,7 +432,7 @@ func TestDomoAPI_ListDatasets(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			domoAPI := tt.api(ctrl)
-			got, err := domoAPI.ListDatasets(tt.args.token.AccessToken)
+			got, err := domoAPI.ListDatasets()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DomoAPI.ListDatasets() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -428,7 +450,6 @@ func TestDomoAPI_GetDatasetIDByName(t *testing.T) {
 
 	type args struct {
 		datasetName string
-		token       Token
 	}
 	tests := []struct {
 		name    string
@@ -440,32 +461,30 @@ func TestDomoAPI_GetDatasetIDByName(t *testing.T) {
 		{
 			name: "success and get dataset's key",
 			args: args{
-				token:       sampleToken,
 				datasetName: "Rene Descartes Mentions",
 			},
 			want: []string{"cc22901d-c856-47c5-89a3-5228a4fa5663"},
 			api: func(ctrl *gomock.Controller) *DomoAPI {
 				rmock := mocks.NewMockRequestHandlerService(ctrl)
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(listDatasetsJSON, 200), nil)
-				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse("[]", 200), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
 		{
 			name: "success ,but key not found",
 			args: args{
-				token:       sampleToken,
 				datasetName: "Not found dataset",
 			},
 			want: nil,
 			api: func(ctrl *gomock.Controller) *DomoAPI {
 				rmock := mocks.NewMockRequestHandlerService(ctrl)
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(listDatasetsJSON, 200), nil)
-				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse("[]", 200), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
@@ -482,6 +501,7 @@ func TestDomoAPI_GetDatasetIDByName(t *testing.T) {
 				rmock.EXPECT().Handler(gomock.Any()).Return(mresp, nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 			wantErr: true,
@@ -492,7 +512,7 @@ func TestDomoAPI_GetDatasetIDByName(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			domoAPI := tt.api(ctrl)
-			got, err := domoAPI.GetDatasetIDByName(tt.args.datasetName, tt.args.token.AccessToken)
+			got, err := domoAPI.GetDatasetIDByName(tt.args.datasetName)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DomoAPI.GetDatasetID() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -506,7 +526,6 @@ func TestDomoAPI_GetDatasetIDByName(t *testing.T) {
 
 func TestDomoAPI_GetDataByDatasetID(t *testing.T) {
 	type args struct {
-		token     Token
 		datasetID string
 		header    bool
 	}
@@ -520,7 +539,6 @@ func TestDomoAPI_GetDataByDatasetID(t *testing.T) {
 		{
 			name: "success and get dataset's key",
 			args: args{
-				token:     sampleToken,
 				datasetID: "dummy_id",
 			},
 			want: csvResponse,
@@ -530,13 +548,13 @@ func TestDomoAPI_GetDataByDatasetID(t *testing.T) {
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(csvResponse, 200), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
 		{
 			name: "success but get empty response",
 			args: args{
-				token:     sampleToken,
 				datasetID: "dummy_id",
 			},
 			want: "",
@@ -546,13 +564,13 @@ func TestDomoAPI_GetDataByDatasetID(t *testing.T) {
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(emptyJSON, 200), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
 		{
 			name: "error occured",
 			args: args{
-				token:     sampleToken,
 				datasetID: "dummy_id",
 			},
 			wantErr: true,
@@ -562,6 +580,7 @@ func TestDomoAPI_GetDataByDatasetID(t *testing.T) {
 				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 500), nil)
 				return &DomoAPI{
 					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
 				}
 			},
 		},
@@ -571,7 +590,7 @@ func TestDomoAPI_GetDataByDatasetID(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			domoAPI := tt.api(ctrl)
-			got, err := domoAPI.GetDataByDatasetID(tt.args.token.AccessToken, tt.args.datasetID, tt.args.header)
+			got, err := domoAPI.GetDataByDatasetID(tt.args.datasetID, tt.args.header)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DomoAPI.GetDataByDatasetID() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -582,3 +601,349 @@ func TestDomoAPI_GetDataByDatasetID(t *testing.T) {
 		})
 	}
 }
+
+func TestDomoAPI_ListDatasetsContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		if req.Context() != ctx {
+			t.Errorf("request context was not propagated")
+		}
+		cancel()
+		//a full page, so the listing would go on
+		return getMockResponse(fullPageJSON(MaxPageSize), 200), nil
+	})
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	got, err := domoAPI.ListDatasetsContext(ctx)
+	if err != context.Canceled {
+		t.Errorf("DomoAPI.ListDatasetsContext() error = %v, want %v", err, context.Canceled)
+	}
+	if got != nil {
+		t.Errorf("DomoAPI.ListDatasetsContext() = %v, want nil", got)
+	}
+}
+
+type contextHandler struct {
+	ctx context.Context
+}
+
+func (h *contextHandler) Handler(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("Handler should not be called")
+}
+
+func (h *contextHandler) HandlerContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	h.ctx = ctx
+	return getMockResponse(csvResponse, 200), nil
+}
+
+func TestDomoAPI_GetDataByDatasetIDContext(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	h := &contextHandler{}
+	domoAPI := &DomoAPI{
+		requestHandlerService: h,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+	got, err := domoAPI.GetDataByDatasetIDContext(ctx, "dummy_id", false)
+	if err != nil {
+		t.Errorf("DomoAPI.GetDataByDatasetIDContext() error = %v", err)
+		return
+	}
+	if got != csvResponse {
+		t.Errorf("DomoAPI.GetDataByDatasetIDContext() = %v, want %v", got, csvResponse)
+	}
+	if h.ctx == nil || h.ctx.Value(ctxKey{}) != "value" {
+		t.Errorf("HandlerContext did not receive the caller's context")
+	}
+}
+
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+var datasetMethodCalls = []struct {
+	name     string
+	okBody   string
+	okStatus int
+	call     func(d *DomoAPI) error
+}{
+	{
+		name:     "CreateAccessToken",
+		okBody:   tokenAPIRespJSON,
+		okStatus: 200,
+		call: func(d *DomoAPI) error {
+			_, err := d.CreateAccessToken()
+			return err
+		},
+	},
+	{
+		name:     "CreateDataset",
+		okBody:   createDatasetOKJson,
+		okStatus: 201,
+		call: func(d *DomoAPI) error {
+			_, err := d.CreateDataset(DomoDataset{Name: "Leonhard Euler Party"})
+			return err
+		},
+	},
+	{
+		name:     "AddDataToDataset append",
+		okStatus: 204,
+		call: func(d *DomoAPI) error {
+			return d.AddDataToDataset("ds_id001", "1,1,1,1", false)
+		},
+	},
+	{
+		name:     "AddDataToDataset replace",
+		okStatus: 204,
+		call: func(d *DomoAPI) error {
+			return d.AddDataToDataset("ds_id001", "1,1,1,1", true)
+		},
+	},
+	{
+		name:     "ListDatasets",
+		okBody:   "[]",
+		okStatus: 200,
+		call: func(d *DomoAPI) error {
+			_, err := d.ListDatasets()
+			return err
+		},
+	},
+	{
+		name:     "GetDatasetIDByName",
+		okBody:   "[]",
+		okStatus: 200,
+		call: func(d *DomoAPI) error {
+			_, err := d.GetDatasetIDByName("Rene Descartes Mentions")
+			return err
+		},
+	},
+	{
+		name:     "GetDataByDatasetID",
+		okBody:   csvResponse,
+		okStatus: 200,
+		call: func(d *DomoAPI) error {
+			_, err := d.GetDataByDatasetID("dummy_id", true)
+			return err
+		},
+	},
+}
+
+func TestDomoAPI_TransportError(t *testing.T) {
+	transportErr := fmt.Errorf("dial tcp: connection refused")
+
+	for _, tt := range datasetMethodCalls {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			rmock := mocks.NewMockRequestHandlerService(ctrl)
+			rmock.EXPECT().Handler(gomock.Any()).Return(nil, transportErr)
+			domoAPI := &DomoAPI{
+				requestHandlerService: rmock,
+				tokens:                &tokenSource{token: &sampleToken},
+			}
+
+			if err := tt.call(domoAPI); err != transportErr {
+				t.Errorf("DomoAPI.%s() error = %v, want %v", tt.name, err, transportErr)
+			}
+		})
+	}
+}
+
+func TestDomoAPI_ClosesResponseBody(t *testing.T) {
+	for _, tt := range datasetMethodCalls {
+		for _, statusCode := range []int{tt.okStatus, 500} {
+			t.Run(fmt.Sprintf("%s %d", tt.name, statusCode), func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				body := tt.okBody
+				if statusCode != tt.okStatus {
+					body = errorJSON
+				}
+				tracked := &trackedBody{Reader: strings.NewReader(body)}
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).Return(&http.Response{StatusCode: statusCode, Body: tracked}, nil)
+				domoAPI := &DomoAPI{
+					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+
+				err := tt.call(domoAPI)
+				if (err != nil) != (statusCode != tt.okStatus) {
+					t.Errorf("DomoAPI.%s() error = %v", tt.name, err)
+				}
+				var apiErr *APIError
+				if statusCode != tt.okStatus && !errors.As(err, &apiErr) {
+					t.Errorf("DomoAPI.%s() error = %v, want *APIError", tt.name, err)
+				}
+				if !tracked.closed {
+					t.Errorf("DomoAPI.%s() did not close the response body", tt.name)
+				}
+			})
+		}
+	}
+}
+
+func TestDomoAPI_GetDataset(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		want     *DomoDataset
+		wantErr  bool
+		notFound bool
+		api      func(ctrl *gomock.Controller) *DomoAPI
+	}{
+		{
+			name: "success and return dataset",
+			id:   "4405ff58-1957-45f0-82bd-914d989a3ea3",
+			want: &DomoDataset{
+				Name:  "Leonhard Euler Party",
+				Owner: &Owner{Name: "DomoSupport", ID: 27},
+			},
+			api: func(ctrl *gomock.Controller) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					if req.Method != http.MethodGet || req.URL.Path != "/v1/datasets/4405ff58-1957-45f0-82bd-914d989a3ea3" {
+						t.Errorf("request = %v %v", req.Method, req.URL)
+					}
+					return getMockResponse(createDatasetOKJson, 200), nil
+				})
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+		{
+			name:     "not found",
+			id:       "unknown",
+			wantErr:  true,
+			notFound: true,
+			api: func(ctrl *gomock.Controller) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(notFoundJSON, 404), nil)
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+		{
+			name:    "missing dataset id",
+			wantErr: true,
+			api: func(ctrl *gomock.Controller) *DomoAPI {
+				return &DomoAPI{
+					requestHandlerService: mocks.NewMockRequestHandlerService(ctrl),
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			domoAPI := tt.api(ctrl)
+
+			got, err := domoAPI.GetDataset(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DomoAPI.GetDataset() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if IsNotFound(err) != tt.notFound {
+				t.Errorf("IsNotFound(%v) = %v, want %v", err, IsNotFound(err), tt.notFound)
+			}
+			if !tt.wantErr {
+				if got.Name != tt.want.Name || !reflect.DeepEqual(got.Owner, tt.want.Owner) || got.Schema == nil || len(got.Schema.Columns) != 2 {
+					t.Errorf("DomoAPI.GetDataset() = %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDomoAPI_UpdateDataset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPut || req.URL.Path != "/v1/datasets/4405ff58-1957-45f0-82bd-914d989a3ea3" {
+			t.Errorf("request = %v %v", req.Method, req.URL)
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		var sent map[string]interface{}
+		_ = json.Unmarshal(body, &sent)
+		if _, ok := sent["owner"]; ok {
+			t.Errorf("request body = %s, owner cannot be updated", body)
+		}
+		if sent["name"] != "Leonhard Euler Party" {
+			t.Errorf("request body = %s", body)
+		}
+		return getMockResponse(createDatasetOKJson, 200), nil
+	})
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	got, err := domoAPI.UpdateDatasetContext(context.Background(), "4405ff58-1957-45f0-82bd-914d989a3ea3", DomoDataset{
+		Name:  "Leonhard Euler Party",
+		Owner: &Owner{ID: 1},
+	})
+	if err != nil {
+		t.Fatalf("DomoAPI.UpdateDatasetContext() error = %v", err)
+	}
+	if got.Name != "Leonhard Euler Party" {
+		t.Errorf("DomoAPI.UpdateDatasetContext() = %+v", got)
+	}
+}
+
+func TestDomoAPI_DeleteDataset(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{name: "deleted", statusCode: 204},
+		{name: "not found", statusCode: 404, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rmock := mocks.NewMockRequestHandlerService(ctrl)
+			rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				if req.Method != http.MethodDelete || req.URL.Path != "/v1/datasets/ds_id001" {
+					t.Errorf("request = %v %v", req.Method, req.URL)
+				}
+				return getMockResponse("", tt.statusCode), nil
+			})
+			domoAPI := &DomoAPI{
+				requestHandlerService: rmock,
+				tokens:                &tokenSource{token: &sampleToken},
+			}
+
+			if err := domoAPI.DeleteDatasetContext(context.Background(), "ds_id001"); (err != nil) != tt.wantErr {
+				t.Errorf("DomoAPI.DeleteDatasetContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package domoapi
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//domoDateTimeLayout is the layout DATETIME values are written with, always in UTC
+const domoDateTimeLayout = "2006-01-02T15:04:05Z"
+
+//decimalScale is the number of fractional digits written for DECIMAL values which are not exact decimals
+const decimalScale = 18
+
+//Encoder writes Go structs or maps as csv rows in the column order of a Schema.
+//Values are formatted for domo: ISO dates, empty values for nil and RFC 4180 quoting.
+//Struct fields are matched to columns like Rows.Scan does.
+type Encoder struct {
+	w      *csv.Writer
+	schema *Schema
+	record []string
+}
+
+//NewEncoder creates an Encoder writing rows of schema to w
+func NewEncoder(w io.Writer, schema *Schema) (*Encoder, error) {
+	if schema == nil || len(schema.Columns) == 0 {
+		return nil, fmt.Errorf("error: schema has no columns")
+	}
+	for _, c := range schema.Columns {
+		if !validColumnType(c.Type) {
+			return nil, fmt.Errorf("error: column %s has unknown type %q", c.Name, c.Type)
+		}
+	}
+	return &Encoder{
+		w:      csv.NewWriter(w),
+		schema: schema,
+		record: make([]string, len(schema.Columns)),
+	}, nil
+}
+
+func validColumnType(columnType string) bool {
+	switch columnType {
+	case ColumnTypeString, ColumnTypeLong, ColumnTypeDouble, ColumnTypeDecimal, ColumnTypeDate, ColumnTypeDateTime:
+		return true
+	}
+	return false
+}
+
+//Encode writes one row. row is a struct, a pointer to a struct or a map[string]interface{}.
+//Every schema column needs a struct field, while map keys which are missing are written as nil.
+//Tagged struct fields and map keys which are not in the schema are reported as error.
+func (e *Encoder) Encode(row interface{}) error {
+	if m, ok := row.(map[string]interface{}); ok {
+		return e.encodeMap(m)
+	}
+	v := reflect.ValueOf(row)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fmt.Errorf("error: cannot encode nil row")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("error: cannot encode %T, need a struct or map[string]interface{}", row)
+	}
+	fields, err := e.schemaFields(v.Type())
+	if err != nil {
+		return err
+	}
+	for i, c := range e.schema.Columns {
+		value, ok := fieldValue(v, fields[i].index)
+		var x interface{}
+		if ok {
+			x = value.Interface()
+		}
+		s, err := FormatValue(c.Type, x)
+		if err != nil {
+			return fmt.Errorf("error: column %s - %v", c.Name, err)
+		}
+		e.record[i] = s
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *Encoder) encodeMap(m map[string]interface{}) error {
+	for key := range m {
+		if !e.hasColumn(key) {
+			return fmt.Errorf("error: column %s is not in the schema", key)
+		}
+	}
+	for i, c := range e.schema.Columns {
+		s, err := FormatValue(c.Type, m[c.Name])
+		if err != nil {
+			return fmt.Errorf("error: column %s - %v", c.Name, err)
+		}
+		e.record[i] = s
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *Encoder) hasColumn(name string) bool {
+	for _, c := range e.schema.Columns {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+//schemaFields returns the field of struct type t for each schema column
+func (e *Encoder) schemaFields(t reflect.Type) ([]structField, error) {
+	fields := typeFields(t)
+	matched := make([]structField, len(e.schema.Columns))
+	for i, c := range e.schema.Columns {
+		f, ok := fieldByName(fields, c.Name)
+		if !ok {
+			return nil, fmt.Errorf("error: %s has no field for column %s", t, c.Name)
+		}
+		if f.columnType != "" && f.columnType != c.Type {
+			return nil, fmt.Errorf("error: field %s of %s is tagged %s, but column %s is %s", f.name, t, f.columnType, c.Name, c.Type)
+		}
+		matched[i] = f
+	}
+	for _, f := range fields {
+		if f.tagged && !e.hasColumn(f.name) {
+			return nil, fmt.Errorf("error: field %s of %s is not in the schema", f.name, t)
+		}
+	}
+	return matched, nil
+}
+
+//fieldValue returns the field of v, or false when a nil embedded struct pointer is on the way
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+//Flush writes buffered rows to the underlying writer
+func (e *Encoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+//FormatValue formats v as csv value of the given domo column type. nil and nil pointers are written as empty value.
+func FormatValue(columnType string, v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "", nil
+		}
+		if _, ok := rv.Interface().(*big.Rat); ok {
+			break
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return "", nil
+	}
+	v = rv.Interface()
+
+	switch columnType {
+	case ColumnTypeString:
+		switch x := v.(type) {
+		case string:
+			return x, nil
+		case []byte:
+			return string(x), nil
+		case fmt.Stringer:
+			return x.String(), nil
+		}
+		return fmt.Sprint(v), nil
+	case ColumnTypeLong:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(rv.Int(), 10), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return strconv.FormatUint(rv.Uint(), 10), nil
+		}
+	case ColumnTypeDouble:
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return formatFloat(rv, columnType)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(rv.Int(), 10), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return strconv.FormatUint(rv.Uint(), 10), nil
+		}
+	case ColumnTypeDecimal:
+		switch x := v.(type) {
+		case *big.Rat:
+			return formatRat(x), nil
+		case big.Rat:
+			return formatRat(&x), nil
+		case *big.Float:
+			return formatBigFloat(x, columnType)
+		case big.Float:
+			return formatBigFloat(&x, columnType)
+		}
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return formatFloat(rv, columnType)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(rv.Int(), 10), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return strconv.FormatUint(rv.Uint(), 10), nil
+		}
+	case ColumnTypeDate:
+		switch x := v.(type) {
+		case Date:
+			if x.IsZero() {
+				return "", nil
+			}
+			return x.String(), nil
+		case time.Time:
+			if x.IsZero() {
+				return "", nil
+			}
+			return x.Format(dateLayout), nil
+		}
+	case ColumnTypeDateTime:
+		if t, ok := v.(time.Time); ok {
+			if t.IsZero() {
+				return "", nil
+			}
+			return t.UTC().Format(domoDateTimeLayout), nil
+		}
+	default:
+		return "", fmt.Errorf("unknown column type %q", columnType)
+	}
+	return "", fmt.Errorf("cannot write %T as %s", v, columnType)
+}
+
+//formatFloat writes a float32 or float64 in the shortest form reading back as the same value.
+//NaN and infinities have no csv representation in Domo.
+func formatFloat(rv reflect.Value, columnType string) (string, error) {
+	f := rv.Float()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("cannot write %v as %s", f, columnType)
+	}
+	bitSize := 64
+	if rv.Kind() == reflect.Float32 {
+		bitSize = 32
+	}
+	return strconv.FormatFloat(f, 'f', -1, bitSize), nil
+}
+
+func formatBigFloat(f *big.Float, columnType string) (string, error) {
+	if f.IsInf() {
+		return "", fmt.Errorf("cannot write %v as %s", f, columnType)
+	}
+	return f.Text('f', -1), nil
+}
+
+//formatRat writes r as decimal number, exact when r has a finite decimal representation
+//of at most decimalScale digits; smaller values are rounded, possibly to 0
+func formatRat(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	s := strings.TrimRight(strings.TrimRight(r.FloatString(decimalScale), "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+//ImportRows encodes rows with the given schema and streams them into the dataset.
+//rows is a slice, an array or a receive channel of structs or maps as accepted by Encoder.Encode;
+//a channel is read until it is closed, but is left undrained when the upload fails.
+func (d *DomoAPI) ImportRows(ctx context.Context, datasetID string, schema *Schema, rows interface{}, opts ImportOptions) error {
+	next, err := rowIterator(rows)
+	if err != nil {
+		return err
+	}
+	pr, pw := io.Pipe()
+	//stops the encoding goroutine if the upload ends before reading everything
+	defer pr.Close()
+	enc, err := NewEncoder(pw, schema)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		var err error
+		for {
+			row, ok := next()
+			if !ok {
+				break
+			}
+			if err = enc.Encode(row); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = enc.Flush()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return d.ImportDataset(ctx, datasetID, pr, opts)
+}
+
+//rowIterator returns a function yielding the elements of a slice, array or channel
+func rowIterator(rows interface{}) (func() (interface{}, bool), error) {
+	v := reflect.ValueOf(rows)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		i := 0
+		return func() (interface{}, bool) {
+			if i >= v.Len() {
+				return nil, false
+			}
+			i++
+			return v.Index(i - 1).Interface(), true
+		}, nil
+	case reflect.Chan:
+		if v.Type().ChanDir()&reflect.RecvDir == 0 {
+			return nil, fmt.Errorf("error: cannot receive rows from %T", rows)
+		}
+		return func() (interface{}, bool) {
+			x, ok := v.Recv()
+			if !ok {
+				return nil, false
+			}
+			return x.Interface(), true
+		}, nil
+	}
+	return nil, fmt.Errorf("error: rows must be a slice, an array or a channel, got %T", rows)
+}
@@ -0,0 +1,214 @@
+package domoapi
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+var partySchema = &Schema{
+	Columns: []Column{
+		{Type: ColumnTypeString, Name: "Friend"},
+		{Type: ColumnTypeLong, Name: "Guests"},
+		{Type: ColumnTypeDouble, Name: "Score"},
+		{Type: ColumnTypeDecimal, Name: "Budget"},
+		{Type: ColumnTypeDate, Name: "Day"},
+		{Type: ColumnTypeDateTime, Name: "Arrival"},
+	},
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	guests := 3
+	arrival := time.Date(1777, 4, 30, 19, 15, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name    string
+		rows    []interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "struct",
+			rows: []interface{}{
+				partyRow{
+					Friend:  "Gauss",
+					Guests:  &guests,
+					Score:   9.5,
+					Budget:  big.NewRat(123456, 100),
+					Day:     time.Date(1777, 4, 30, 0, 0, 0, 0, time.UTC),
+					Arrival: &arrival,
+				},
+			},
+			want: "Gauss,3,9.5,1234.56,1777-04-30,1777-04-30T18:15:00Z\n",
+		},
+		{
+			name: "quoting and nulls",
+			rows: []interface{}{
+				&partyRow{Friend: "Bernoulli, \"Daniel\""},
+			},
+			want: "\"Bernoulli, \"\"Daniel\"\"\",,0,,,\n",
+		},
+		{
+			name: "map",
+			rows: []interface{}{
+				map[string]interface{}{"Friend": "Euler", "Guests": int64(7), "Budget": 10.25},
+			},
+			want: "Euler,7,,10.25,,\n",
+		},
+		{
+			name: "float32",
+			rows: []interface{}{
+				map[string]interface{}{"Friend": "Euler", "Score": float32(0.1), "Budget": float32(10.1)},
+			},
+			want: "Euler,,0.1,10.1,,\n",
+		},
+		{
+			name:    "NaN",
+			rows:    []interface{}{map[string]interface{}{"Score": math.NaN()}},
+			wantErr: true,
+		},
+		{
+			name:    "infinity",
+			rows:    []interface{}{map[string]interface{}{"Budget": math.Inf(-1)}},
+			wantErr: true,
+		},
+		{
+			name:    "map key not in schema",
+			rows:    []interface{}{map[string]interface{}{"Enemy": "Leibniz"}},
+			wantErr: true,
+		},
+		{
+			name: "struct without field for a column",
+			rows: []interface{}{struct {
+				Friend string
+			}{Friend: "Euler"}},
+			wantErr: true,
+		},
+		{
+			name:    "wrong value type",
+			rows:    []interface{}{map[string]interface{}{"Guests": "seven"}},
+			wantErr: true,
+		},
+		{
+			name:    "not a row",
+			rows:    []interface{}{42},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc, err := NewEncoder(&buf, partySchema)
+			if err != nil {
+				t.Fatalf("NewEncoder() error = %v", err)
+			}
+			for _, row := range tt.rows {
+				if err = enc.Encode(row); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Encoder.Encode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err := enc.Flush(); err != nil {
+				t.Fatalf("Encoder.Flush() error = %v", err)
+			}
+			if !tt.wantErr && buf.String() != tt.want {
+				t.Errorf("Encoder.Encode() wrote %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEncoder_InvalidSchema(t *testing.T) {
+	if _, err := NewEncoder(ioutil.Discard, &Schema{}); err == nil {
+		t.Errorf("NewEncoder() with empty schema error = nil")
+	}
+	if _, err := NewEncoder(ioutil.Discard, &Schema{Columns: []Column{{Name: "a", Type: "BOOLEAN"}}}); err == nil {
+		t.Errorf("NewEncoder() with unknown column type error = nil")
+	}
+}
+
+func TestDomoAPI_ImportRows(t *testing.T) {
+	schema := &Schema{Columns: []Column{
+		{Type: ColumnTypeString, Name: "Friend"},
+		{Type: ColumnTypeString, Name: "Attending"},
+	}}
+	type guest struct {
+		Friend    string
+		Attending string
+	}
+	ch := make(chan guest, 2)
+	ch <- guest{"Euler", "yes"}
+	ch <- guest{"Gauss", "no"}
+	close(ch)
+
+	tests := []struct {
+		name    string
+		rows    interface{}
+		wantErr bool
+	}{
+		{name: "slice", rows: []guest{{"Euler", "yes"}, {"Gauss", "no"}}},
+		{name: "channel", rows: ch},
+		{name: "not a collection", rows: guest{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rmock := mocks.NewMockRequestHandlerService(ctrl)
+			if !tt.wantErr {
+				rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					body, _ := ioutil.ReadAll(req.Body)
+					if string(body) != "Euler,yes\nGauss,no\n" {
+						t.Errorf("request body = %q", body)
+					}
+					return getMockResponse("", 204), nil
+				})
+			}
+			domoAPI := &DomoAPI{
+				requestHandlerService: rmock,
+				tokens:                &tokenSource{token: &sampleToken},
+			}
+
+			err := domoAPI.ImportRows(context.Background(), "ds_id001", schema, tt.rows, ImportOptions{Replace: true})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DomoAPI.ImportRows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_formatRat(t *testing.T) {
+	tiny, _ := new(big.Rat).SetString("1e-20")
+	almostOne, _ := new(big.Rat).SetString("0.9999999999999999999999")
+	tests := []struct {
+		name string
+		r    *big.Rat
+		want string
+	}{
+		{name: "integer", r: big.NewRat(-42, 1), want: "-42"},
+		{name: "decimal", r: big.NewRat(123456, 100), want: "1234.56"},
+		{name: "negative decimal", r: big.NewRat(-1, 8), want: "-0.125"},
+		{name: "below the scale", r: tiny, want: "0"},
+		{name: "negative below the scale", r: new(big.Rat).Neg(tiny), want: "0"},
+		{name: "rounded up to an integer", r: almostOne, want: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatRat(tt.r); got != tt.want {
+				t.Errorf("formatRat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package domoapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+//requestIDHeaders are the response headers carrying the id of the request on domo's side
+var requestIDHeaders = []string{"X-Request-Id", "X-Domo-Request-Id"}
+
+//APIError is returned when domo responds with an unexpected http status
+type APIError struct {
+	//StatusCode is the http status code of the response
+	StatusCode int `json:"-"`
+	//Method and URL identify the failed request
+	Method string `json:"-"`
+	URL    string `json:"-"`
+	//RequestID is the request id sent back by domo, if any
+	RequestID string `json:"-"`
+	//Body is the raw response body
+	Body string `json:"-"`
+
+	//Status, StatusReason, Message and Toe are read from domo's json error payload when there is one.
+	//Toe is domo's trace id, quote it when contacting domo support.
+	Status       int    `json:"status,omitempty"`
+	StatusReason string `json:"statusReason,omitempty"`
+	Message      string `json:"message,omitempty"`
+	Toe          string `json:"toe,omitempty"`
+}
+
+func newAPIError(req *http.Request, resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+	}
+	if req != nil {
+		e.Method = req.Method
+		e.URL = req.URL.String()
+	}
+	for _, h := range requestIDHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+	//the payload is optional, a body which is not json is kept in Body only
+	_ = json.Unmarshal(body, e)
+	return e
+}
+
+//readAPIError reads the rest of resp.Body into a new APIError
+func readAPIError(req *http.Request, resp *http.Response) *APIError {
+	body, _ := ioutil.ReadAll(resp.Body)
+	return newAPIError(req, resp, body)
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "domo api error: %s %s responded with %d", e.Method, e.URL, e.StatusCode)
+	switch {
+	case e.Message != "":
+		fmt.Fprintf(&b, " - %s", e.Message)
+	case e.StatusReason != "":
+		fmt.Fprintf(&b, " - %s", e.StatusReason)
+	case e.Body != "":
+		fmt.Fprintf(&b, " - %s", e.Body)
+	}
+	if e.Toe != "" {
+		fmt.Fprintf(&b, " (toe: %s)", e.Toe)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request id: %s)", e.RequestID)
+	}
+	return b.String()
+}
+
+//IsNotFound reports whether err is an APIError for a 404 response
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+//IsUnauthorized reports whether err is an APIError for a 401 response
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized)
+}
+
+//IsForbidden reports whether err is an APIError for a 403 response, e.g. because the token lacks a scope
+func IsForbidden(err error) bool {
+	return hasStatusCode(err, http.StatusForbidden)
+}
+
+//IsRateLimited reports whether err is an APIError for a 429 response
+func IsRateLimited(err error) bool {
+	return hasStatusCode(err, http.StatusTooManyRequests)
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
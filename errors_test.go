@@ -0,0 +1,123 @@
+package domoapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+var notFoundJSON = `{
+	"status": 404,
+	"statusReason": "Not Found",
+	"message": "DataSet not found",
+	"toe": "5XSLDN3N8N-4U7VQ-QUG1R"
+}`
+
+func TestNewAPIError(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://api.domo.example.com/v1/datasets/dummy_id", nil)
+
+	tests := []struct {
+		name        string
+		resp        *http.Response
+		body        string
+		want        APIError
+		wantMessage string
+	}{
+		{
+			name: "domo json payload",
+			resp: &http.Response{
+				StatusCode: 404,
+				Header:     http.Header{"X-Request-Id": []string{"req-1"}},
+			},
+			body: notFoundJSON,
+			want: APIError{
+				StatusCode:   404,
+				Method:       http.MethodGet,
+				URL:          "https://api.domo.example.com/v1/datasets/dummy_id",
+				RequestID:    "req-1",
+				Body:         notFoundJSON,
+				Status:       404,
+				StatusReason: "Not Found",
+				Message:      "DataSet not found",
+				Toe:          "5XSLDN3N8N-4U7VQ-QUG1R",
+			},
+			wantMessage: "DataSet not found",
+		},
+		{
+			name: "plain text body",
+			resp: &http.Response{StatusCode: 502},
+			body: "Bad Gateway",
+			want: APIError{
+				StatusCode: 502,
+				Method:     http.MethodGet,
+				URL:        "https://api.domo.example.com/v1/datasets/dummy_id",
+				Body:       "Bad Gateway",
+			},
+			wantMessage: "Bad Gateway",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newAPIError(req, tt.resp, []byte(tt.body))
+			if *got != tt.want {
+				t.Errorf("newAPIError() = %+v, want %+v", *got, tt.want)
+			}
+			if !strings.Contains(got.Error(), tt.wantMessage) {
+				t.Errorf("APIError.Error() = %v, want it to contain %v", got.Error(), tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestIsStatusHelpers(t *testing.T) {
+	wrap := func(statusCode int) error {
+		return fmt.Errorf("wrapped: %w", &APIError{StatusCode: statusCode})
+	}
+	tests := []struct {
+		name  string
+		check func(error) bool
+		err   error
+		want  bool
+	}{
+		{name: "not found", check: IsNotFound, err: wrap(404), want: true},
+		{name: "unauthorized", check: IsUnauthorized, err: wrap(401), want: true},
+		{name: "forbidden", check: IsForbidden, err: wrap(403), want: true},
+		{name: "rate limited", check: IsRateLimited, err: wrap(429), want: true},
+		{name: "other status", check: IsNotFound, err: wrap(500), want: false},
+		{name: "not an api error", check: IsNotFound, err: errors.New("boom"), want: false},
+		{name: "nil", check: IsNotFound, err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.check(tt.err); got != tt.want {
+				t.Errorf("check(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomoAPI_CreateDataset_APIError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(`{"status":400,"statusReason":"Bad Request","message":"Invalid schema"}`, 400), nil)
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	_, err := domoAPI.CreateDataset(DomoDataset{Name: "Leonhard Euler Party"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("DomoAPI.CreateDataset() error = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != 400 || apiErr.Message != "Invalid schema" || apiErr.Method != http.MethodPost {
+		t.Errorf("DomoAPI.CreateDataset() error = %+v", apiErr)
+	}
+}
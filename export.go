@@ -0,0 +1,77 @@
+package domoapi
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+//ExportOptions configures a dataset export
+type ExportOptions struct {
+	//IncludeHeader adds the column names as first csv line
+	IncludeHeader bool
+	//Gzip asks domo to compress the export on the wire. The data read from the export is always plain csv
+	Gzip bool
+}
+
+//ExportDataset streams the csv data of the given dataset without buffering it.
+//The caller must close the returned reader.
+func (d *DomoAPI) ExportDataset(ctx context.Context, datasetID string, opts ExportOptions) (io.ReadCloser, error) {
+	if datasetID == "" {
+		return nil, fmt.Errorf("error: missing datasetID")
+	}
+	path := "/v1/datasets/" + datasetID + "/data"
+	if opts.IncludeHeader {
+		path += "?includeHeader=true"
+	}
+	req, err := d.newRequest(ctx, http.MethodGet, path, nil, "text/csv")
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/csv")
+	if opts.Gzip {
+		//setting the header ourselves turns off the transparent decompression of net/http
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	resp, err := d.doStream(req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		return resp.Body, nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return &gzipReadCloser{Reader: zr, body: resp.Body}, nil
+}
+
+//ExportDatasetTo copies the csv data of the given dataset into w with constant memory
+//and returns the number of bytes written
+func (d *DomoAPI) ExportDatasetTo(ctx context.Context, datasetID string, w io.Writer, opts ExportOptions) (int64, error) {
+	r, err := d.ExportDataset(ctx, datasetID, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return io.Copy(w, r)
+}
+
+//gzipReadCloser decompresses body and closes it along with the gzip reader
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (r *gzipReadCloser) Close() error {
+	zerr := r.Reader.Close()
+	if err := r.body.Close(); err != nil {
+		return err
+	}
+	return zerr
+}
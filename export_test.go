@@ -0,0 +1,159 @@
+package domoapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+func getGzipMockResponse(data string, statusCode int) *http.Response {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(data))
+	zw.Close()
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Encoding": []string{"gzip"}},
+		Body:       ioutil.NopCloser(&buf),
+	}
+}
+
+func TestDomoAPI_ExportDataset(t *testing.T) {
+	type args struct {
+		datasetID string
+		opts      ExportOptions
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+		api     func(ctrl *gomock.Controller) *DomoAPI
+	}{
+		{
+			name: "stream plain csv with header",
+			args: args{
+				datasetID: "dummy_id",
+				opts:      ExportOptions{IncludeHeader: true},
+			},
+			want: csvResponse,
+			api: func(ctrl *gomock.Controller) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != "/v1/datasets/dummy_id/data" || req.URL.Query().Get("includeHeader") != "true" {
+						t.Errorf("request url = %v", req.URL)
+					}
+					if req.Header.Get("Accept-Encoding") != "" {
+						t.Errorf("Accept-Encoding = %v, want none", req.Header.Get("Accept-Encoding"))
+					}
+					return getMockResponse(csvResponse, 200), nil
+				})
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+		{
+			name: "decompress gzip csv",
+			args: args{
+				datasetID: "dummy_id",
+				opts:      ExportOptions{Gzip: true},
+			},
+			want: csvResponse,
+			api: func(ctrl *gomock.Controller) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					if req.Header.Get("Accept-Encoding") != "gzip" {
+						t.Errorf("Accept-Encoding = %v, want gzip", req.Header.Get("Accept-Encoding"))
+					}
+					return getGzipMockResponse(csvResponse, 200), nil
+				})
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+		{
+			name: "error occured",
+			args: args{
+				datasetID: "dummy_id",
+			},
+			wantErr: true,
+			api: func(ctrl *gomock.Controller) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 500), nil)
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+		{
+			name:    "missing datasetID",
+			wantErr: true,
+			api: func(ctrl *gomock.Controller) *DomoAPI {
+				return &DomoAPI{
+					requestHandlerService: mocks.NewMockRequestHandlerService(ctrl),
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			domoAPI := tt.api(ctrl)
+
+			r, err := domoAPI.ExportDataset(context.Background(), tt.args.datasetID, tt.args.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DomoAPI.ExportDataset() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			defer r.Close()
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Errorf("reading export error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("DomoAPI.ExportDataset() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestDomoAPI_ExportDatasetTo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tracked := &trackedBody{Reader: bytes.NewReader([]byte(csvResponse))}
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).Return(&http.Response{StatusCode: 200, Body: tracked}, nil)
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	var buf bytes.Buffer
+	n, err := domoAPI.ExportDatasetTo(context.Background(), "dummy_id", &buf, ExportOptions{})
+	if err != nil {
+		t.Fatalf("DomoAPI.ExportDatasetTo() error = %v", err)
+	}
+	if n != int64(len(csvResponse)) || buf.String() != csvResponse {
+		t.Errorf("DomoAPI.ExportDatasetTo() = %d, %v, want %d, %v", n, buf.String(), len(csvResponse), csvResponse)
+	}
+	if !tracked.closed {
+		t.Errorf("DomoAPI.ExportDatasetTo() did not close the response body")
+	}
+}
@@ -0,0 +1,237 @@
+package domoapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+//Group is a Domo group of users. Default and Active are left unchanged by UpdateGroup when nil.
+type Group struct {
+	ID          int64  `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     *bool  `json:"default,omitempty"`
+	Active      *bool  `json:"active,omitempty"`
+	CreatorID   int64  `json:"creatorId,omitempty"`
+	MemberCount int    `json:"memberCount,omitempty"`
+}
+
+//Bool returns a pointer to v, to set optional flags such as Group.Active
+func Bool(v bool) *bool {
+	return &v
+}
+
+//maxGroupPageSize is the largest number of groups or members Domo returns per page
+const maxGroupPageSize = 500
+
+func groupPath(groupID int64) string {
+	return "/v1/groups/" + strconv.FormatInt(groupID, 10)
+}
+
+func groupUserPath(groupID, userID int64) string {
+	return groupPath(groupID) + "/users/" + strconv.FormatInt(userID, 10)
+}
+
+//ListGroups lists all groups of the domo instance
+func (d *DomoAPI) ListGroups(ctx context.Context) ([]Group, error) {
+	var groups []Group
+	for offset := 0; ; offset += maxGroupPageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		path := fmt.Sprintf("/v1/groups?limit=%d&offset=%d", maxGroupPageSize, offset)
+		req, err := d.newRequest(ctx, http.MethodGet, path, nil, "application/json")
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Group
+		if err := d.doJSON(req, &page, http.StatusOK); err != nil {
+			return nil, err
+		}
+		groups = append(groups, page...)
+		if len(page) < maxGroupPageSize {
+			return groups, nil
+		}
+	}
+}
+
+//GetGroup gets a group by ID
+func (d *DomoAPI) GetGroup(ctx context.Context, groupID int64) (*Group, error) {
+	req, err := d.newRequest(ctx, http.MethodGet, groupPath(groupID), nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var g *Group
+	if err := d.doJSON(req, &g, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+//CreateGroup creates a group and returns it with its ID
+func (d *DomoAPI) CreateGroup(ctx context.Context, group Group) (*Group, error) {
+	if group.Name == "" {
+		return nil, fmt.Errorf("error: missing group name")
+	}
+	group.ID = 0
+	req, err := d.newJSONRequest(WithIdempotent(ctx, false), http.MethodPost, "/v1/groups", group)
+	if err != nil {
+		return nil, err
+	}
+
+	var g *Group
+	if err := d.doJSON(req, &g, http.StatusOK, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+//UpdateGroup updates the name, description, active and default flags of a group and returns the updated group.
+//Empty fields are left unchanged.
+func (d *DomoAPI) UpdateGroup(ctx context.Context, groupID int64, group Group) (*Group, error) {
+	group.ID = groupID
+	req, err := d.newJSONRequest(ctx, http.MethodPut, groupPath(groupID), group)
+	if err != nil {
+		return nil, err
+	}
+
+	var g *Group
+	if err := d.doJSON(req, &g, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+//DeleteGroup deletes a group, its members are kept
+func (d *DomoAPI) DeleteGroup(ctx context.Context, groupID int64) error {
+	req, err := d.newRequest(ctx, http.MethodDelete, groupPath(groupID), nil, "")
+	if err != nil {
+		return err
+	}
+	return d.doJSON(req, nil, http.StatusNoContent)
+}
+
+//ListGroupUsers lists the IDs of the members of a group
+func (d *DomoAPI) ListGroupUsers(ctx context.Context, groupID int64) ([]int64, error) {
+	var userIDs []int64
+	for offset := 0; ; offset += maxGroupPageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		path := fmt.Sprintf("%s/users?limit=%d&offset=%d", groupPath(groupID), maxGroupPageSize, offset)
+		req, err := d.newRequest(ctx, http.MethodGet, path, nil, "application/json")
+		if err != nil {
+			return nil, err
+		}
+
+		var page []int64
+		if err := d.doJSON(req, &page, http.StatusOK); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, page...)
+		if len(page) < maxGroupPageSize {
+			return userIDs, nil
+		}
+	}
+}
+
+//AddGroupUsers adds users to a group, users already in the group are left as is
+func (d *DomoAPI) AddGroupUsers(ctx context.Context, groupID int64, userIDs ...int64) error {
+	for _, userID := range userIDs {
+		req, err := d.newRequest(ctx, http.MethodPut, groupUserPath(groupID, userID), nil, "")
+		if err != nil {
+			return err
+		}
+		if err := d.doJSON(req, nil, http.StatusNoContent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//RemoveGroupUsers removes users from a group
+func (d *DomoAPI) RemoveGroupUsers(ctx context.Context, groupID int64, userIDs ...int64) error {
+	for _, userID := range userIDs {
+		req, err := d.newRequest(ctx, http.MethodDelete, groupUserPath(groupID, userID), nil, "")
+		if err != nil {
+			return err
+		}
+		if err := d.doJSON(req, nil, http.StatusNoContent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//GroupMemberChanges are the members added and removed, or to be with DryRun, by SyncGroupUsers
+type GroupMemberChanges struct {
+	Added   []int64
+	Removed []int64
+}
+
+//Empty reports whether the group already had the desired members
+func (c *GroupMemberChanges) Empty() bool {
+	return len(c.Added)+len(c.Removed) == 0
+}
+
+//GroupSyncOptions controls SyncGroupUsers
+type GroupSyncOptions struct {
+	//KeepUnlisted leaves members which are not desired instead of removing them
+	KeepUnlisted bool
+	//DryRun only computes the changes without applying them
+	DryRun bool
+}
+
+//SyncGroupUsers makes the members of a group the desired users, adding the missing ones and removing the others.
+//On error the returned changes hold what was applied so far.
+func (d *DomoAPI) SyncGroupUsers(ctx context.Context, groupID int64, desired []int64, opts GroupSyncOptions) (*GroupMemberChanges, error) {
+	current, err := d.ListGroupUsers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[int64]bool, len(current))
+	for _, id := range current {
+		members[id] = true
+	}
+	want := make(map[int64]bool, len(desired))
+	plan := &GroupMemberChanges{}
+	for _, id := range desired {
+		if !want[id] && !members[id] {
+			plan.Added = append(plan.Added, id)
+		}
+		want[id] = true
+	}
+	if !opts.KeepUnlisted {
+		for _, id := range current {
+			if !want[id] {
+				plan.Removed = append(plan.Removed, id)
+			}
+		}
+	}
+	sort.Slice(plan.Added, func(i, j int) bool { return plan.Added[i] < plan.Added[j] })
+	sort.Slice(plan.Removed, func(i, j int) bool { return plan.Removed[i] < plan.Removed[j] })
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	done := &GroupMemberChanges{}
+	for _, id := range plan.Added {
+		if err := d.AddGroupUsers(ctx, groupID, id); err != nil {
+			return done, err
+		}
+		done.Added = append(done.Added, id)
+	}
+	for _, id := range plan.Removed {
+		if err := d.RemoveGroupUsers(ctx, groupID, id); err != nil {
+			return done, err
+		}
+		done.Removed = append(done.Removed, id)
+	}
+	return done, nil
+}
@@ -0,0 +1,232 @@
+package domoapi
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+const groupJSON = `{
+	"id": 3,
+	"name": "Mathematicians",
+	"default": false,
+	"active": true,
+	"creatorId": 27,
+	"memberCount": 2
+}`
+
+func TestDomoAPI_groupMethods(t *testing.T) {
+	checkGroup := func(t *testing.T, g *Group) {
+		t.Helper()
+		if g == nil || g.ID != 3 || g.Name != "Mathematicians" || g.MemberCount != 2 || g.CreatorID != 27 ||
+			g.Active == nil || !*g.Active || g.Default == nil || *g.Default {
+			t.Errorf("group = %+v", g)
+		}
+	}
+	tests := []struct {
+		name    string
+		request mockedRequest
+		run     func(t *testing.T, d *DomoAPI)
+	}{
+		{
+			name:    "list",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/groups?limit=500&offset=0", response: "[" + groupJSON + "]", statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.ListGroups(context.Background())
+				if err != nil {
+					t.Fatalf("DomoAPI.ListGroups() error = %v", err)
+				}
+				if len(got) != 1 {
+					t.Fatalf("DomoAPI.ListGroups() returned %d groups", len(got))
+				}
+				checkGroup(t, &got[0])
+			},
+		},
+		{
+			name:    "get",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/groups/3", response: groupJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.GetGroup(context.Background(), 3)
+				if err != nil {
+					t.Fatalf("DomoAPI.GetGroup() error = %v", err)
+				}
+				checkGroup(t, got)
+			},
+		},
+		{
+			name:    "create",
+			request: mockedRequest{method: http.MethodPost, uri: "/v1/groups", body: `{"name":"Mathematicians"}`, response: groupJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.CreateGroup(context.Background(), Group{Name: "Mathematicians"})
+				if err != nil {
+					t.Fatalf("DomoAPI.CreateGroup() error = %v", err)
+				}
+				checkGroup(t, got)
+			},
+		},
+		{
+			name:    "update",
+			request: mockedRequest{method: http.MethodPut, uri: "/v1/groups/3", body: `{"id":3,"name":"Mathematicians","active":true}`, response: groupJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.UpdateGroup(context.Background(), 3, Group{Name: "Mathematicians", Active: Bool(true)})
+				if err != nil {
+					t.Fatalf("DomoAPI.UpdateGroup() error = %v", err)
+				}
+				checkGroup(t, got)
+			},
+		},
+		{
+			name: "deactivate",
+			request: mockedRequest{
+				method:     http.MethodPut,
+				uri:        "/v1/groups/3",
+				body:       `{"id":3,"default":false,"active":false}`,
+				response:   `{"id": 3, "name": "Mathematicians", "default": false, "active": false}`,
+				statusCode: 200,
+			},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.UpdateGroup(context.Background(), 3, Group{Default: Bool(false), Active: Bool(false)})
+				if err != nil {
+					t.Fatalf("DomoAPI.UpdateGroup() error = %v", err)
+				}
+				if got.Active == nil || *got.Active || got.Default == nil || *got.Default {
+					t.Errorf("DomoAPI.UpdateGroup() = %+v", got)
+				}
+			},
+		},
+		{
+			name:    "delete",
+			request: mockedRequest{method: http.MethodDelete, uri: "/v1/groups/3", statusCode: 204},
+			run: func(t *testing.T, d *DomoAPI) {
+				if err := d.DeleteGroup(context.Background(), 3); err != nil {
+					t.Errorf("DomoAPI.DeleteGroup() error = %v", err)
+				}
+			},
+		},
+		{
+			name:    "list users",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/groups/3/users?limit=500&offset=0", response: "[27, 12]", statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.ListGroupUsers(context.Background(), 3)
+				if err != nil {
+					t.Fatalf("DomoAPI.ListGroupUsers() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, []int64{27, 12}) {
+					t.Errorf("DomoAPI.ListGroupUsers() = %v, want [27 12]", got)
+				}
+			},
+		},
+		{
+			name:    "add user",
+			request: mockedRequest{method: http.MethodPut, uri: "/v1/groups/3/users/27", statusCode: 204},
+			run: func(t *testing.T, d *DomoAPI) {
+				if err := d.AddGroupUsers(context.Background(), 3, 27); err != nil {
+					t.Errorf("DomoAPI.AddGroupUsers() error = %v", err)
+				}
+			},
+		},
+		{
+			name:    "remove user",
+			request: mockedRequest{method: http.MethodDelete, uri: "/v1/groups/3/users/27", statusCode: 204},
+			run: func(t *testing.T, d *DomoAPI) {
+				if err := d.RemoveGroupUsers(context.Background(), 3, 27); err != nil {
+					t.Errorf("DomoAPI.RemoveGroupUsers() error = %v", err)
+				}
+			},
+		},
+		{
+			name:    "remove user not found",
+			request: mockedRequest{method: http.MethodDelete, uri: "/v1/groups/3/users/28", response: notFoundJSON, statusCode: 404},
+			run: func(t *testing.T, d *DomoAPI) {
+				if err := d.RemoveGroupUsers(context.Background(), 3, 28); !IsNotFound(err) {
+					t.Errorf("DomoAPI.RemoveGroupUsers() error = %v, want not found", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tt.run(t, newMockedDomoAPI(t, ctrl, tt.request))
+		})
+	}
+}
+
+func TestDomoAPI_SyncGroupUsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		desired   []int64
+		opts      GroupSyncOptions
+		want      *GroupMemberChanges
+		wantCalls []string
+	}{
+		{
+			name:    "add and remove",
+			desired: []int64{27, 5, 6, 5},
+			want:    &GroupMemberChanges{Added: []int64{5, 6}, Removed: []int64{12}},
+			wantCalls: []string{
+				"GET /v1/groups/3/users",
+				"PUT /v1/groups/3/users/5",
+				"PUT /v1/groups/3/users/6",
+				"DELETE /v1/groups/3/users/12",
+			},
+		},
+		{
+			name:      "already in sync",
+			desired:   []int64{12, 27},
+			want:      &GroupMemberChanges{},
+			wantCalls: []string{"GET /v1/groups/3/users"},
+		},
+		{
+			name:      "keep unlisted",
+			desired:   []int64{5},
+			opts:      GroupSyncOptions{KeepUnlisted: true},
+			want:      &GroupMemberChanges{Added: []int64{5}},
+			wantCalls: []string{"GET /v1/groups/3/users", "PUT /v1/groups/3/users/5"},
+		},
+		{
+			name:      "dry run",
+			desired:   nil,
+			opts:      GroupSyncOptions{DryRun: true},
+			want:      &GroupMemberChanges{Removed: []int64{12, 27}},
+			wantCalls: []string{"GET /v1/groups/3/users"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var calls []string
+			rmock := mocks.NewMockRequestHandlerService(ctrl)
+			rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, req.Method+" "+req.URL.Path)
+				if req.Method == http.MethodGet {
+					return getMockResponse("[27, 12]", 200), nil
+				}
+				return getMockResponse("", 204), nil
+			}).AnyTimes()
+			domoAPI := &DomoAPI{
+				requestHandlerService: rmock,
+				tokens:                &tokenSource{token: &sampleToken},
+			}
+
+			got, err := domoAPI.SyncGroupUsers(context.Background(), 3, tt.desired, tt.opts)
+			if err != nil {
+				t.Fatalf("DomoAPI.SyncGroupUsers() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DomoAPI.SyncGroupUsers() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package domoapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	//DefaultTimeout is the http client timeout used when Config.Timeout is not set
+	DefaultTimeout = 3 * time.Minute
+	//DefaultMaxIdleConnsPerHost is the number of keep-alive connections kept per host when Config.MaxIdleConnsPerHost is not set
+	DefaultMaxIdleConnsPerHost = 10
+)
+
+//defaultClient is used by a zero value RequestHandler
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+//RequestHandler sends requests with a long-lived http client so connections are pooled and kept alive.
+//Use NewRequestHandler to configure it, the zero value uses a default client.
+type RequestHandler struct {
+	client *http.Client
+}
+
+//NewRequestHandler creates RequestHandler from the http settings of cfg
+//(ProxyURL, Timeout, CAFile, TLSConfig, MaxIdleConns, MaxIdleConnsPerHost and IdleConnTimeout)
+func NewRequestHandler(cfg Config) (*RequestHandler, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.ProxyURL != "" {
+		proxy, err := parseProxyURL(cfg.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+	}
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return &RequestHandler{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+	}, nil
+}
+
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	proxy, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid proxy url %q - %v", proxyURL, err)
+	}
+	if proxy.Scheme == "" || proxy.Host == "" {
+		return nil, fmt.Errorf("error: invalid proxy url %q - scheme and host are required", proxyURL)
+	}
+	return proxy, nil
+}
+
+//newTLSConfig returns cfg.TLSConfig with the CA bundle from cfg.CAFile added to its root CAs,
+//or nil when neither is set
+func newTLSConfig(cfg Config) (*tls.Config, error) {
+	if cfg.CAFile == "" {
+		return cfg.TLSConfig, nil
+	}
+	pem, err := ioutil.ReadFile(cfg.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("error: cannot read ca file - %v", err)
+	}
+
+	tlsConfig := &tls.Config{}
+	if cfg.TLSConfig != nil {
+		tlsConfig = cfg.TLSConfig.Clone()
+	}
+	if tlsConfig.RootCAs == nil {
+		//SystemCertPool returns a copy, it can be added to
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		tlsConfig.RootCAs = pool
+	} else {
+		//Clone shares RootCAs, copy it before adding the ca file
+		pool, err := cloneCertPool(tlsConfig.RootCAs)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("error: no certificates found in ca file %s", cfg.CAFile)
+	}
+	return tlsConfig, nil
+}
+
+//HandlerContext handles http client request within ctx.
+func (r *RequestHandler) HandlerContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return r.Handler(req.WithContext(ctx))
+}
+
+//Handler handles http client request.
+func (r *RequestHandler) Handler(req *http.Request) (*http.Response, error) {
+	client := r.client
+	if client == nil {
+		client = defaultClient
+	}
+	return client.Do(req)
+}
@@ -0,0 +1,147 @@
+package domoapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewRequestHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		wantTimeout time.Duration
+		wantProxy   string
+		wantErr     bool
+	}{
+		{
+			name:        "defaults",
+			wantTimeout: DefaultTimeout,
+		},
+		{
+			name: "proxy and timeout",
+			cfg: Config{
+				ProxyURL: "https://proxy_dummy.example.com:8080",
+				Timeout:  time.Second,
+			},
+			wantTimeout: time.Second,
+			wantProxy:   "https://proxy_dummy.example.com:8080",
+		},
+		{
+			name:    "proxy url without scheme",
+			cfg:     Config{ProxyURL: "proxy_dummy.example.com:8080"},
+			wantErr: true,
+		},
+		{
+			name:    "unparsable proxy url",
+			cfg:     Config{ProxyURL: "http://proxy dummy:80%"},
+			wantErr: true,
+		},
+		{
+			name:    "missing ca file",
+			cfg:     Config{CAFile: filepath.Join(os.TempDir(), "does-not-exist.pem")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRequestHandler(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRequestHandler() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.client.Timeout != tt.wantTimeout {
+				t.Errorf("NewRequestHandler() timeout = %v, want %v", got.client.Timeout, tt.wantTimeout)
+			}
+			transport := got.client.Transport.(*http.Transport)
+			if transport.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost {
+				t.Errorf("NewRequestHandler() MaxIdleConnsPerHost = %v, want %v", transport.MaxIdleConnsPerHost, DefaultMaxIdleConnsPerHost)
+			}
+			if tt.wantProxy != "" {
+				req, _ := http.NewRequest(http.MethodGet, "https://api.domo.example.com", nil)
+				proxy, _ := transport.Proxy(req)
+				if proxy == nil || proxy.String() != tt.wantProxy {
+					t.Errorf("NewRequestHandler() proxy = %v, want %v", proxy, tt.wantProxy)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestHandler_CAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "domoapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := NewRequestHandler(Config{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("NewRequestHandler() error = %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := h.Handler(req)
+	if err != nil {
+		t.Fatalf("RequestHandler.Handler() error = %v", err)
+	}
+	resp.Body.Close()
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := (&RequestHandler{}).Handler(req); err == nil {
+		t.Errorf("RequestHandler.Handler() without ca file trusted an unknown certificate")
+	}
+}
+
+func TestRequestHandler_CAFileKeepsCallerPool(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "domoapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	h, err := NewRequestHandler(Config{CAFile: caFile, TLSConfig: &tls.Config{RootCAs: pool}})
+	if _, verr := server.Certificate().Verify(x509.VerifyOptions{Roots: pool}); verr == nil {
+		t.Errorf("NewRequestHandler() added the ca file to the caller's pool")
+	}
+	if err != nil {
+		//go versions before 1.19 cannot copy the pool
+		return
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := h.Handler(req)
+	if err != nil {
+		t.Fatalf("RequestHandler.Handler() error = %v", err)
+	}
+	resp.Body.Close()
+}
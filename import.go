@@ -0,0 +1,64 @@
+package domoapi
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+//ImportOptions configures a dataset import
+type ImportOptions struct {
+	//Replace resets the dataset's data with the imported data (updateMethod=REPLACE) instead of appending to it
+	Replace bool
+	//Gzip compresses the data on the fly while it is uploaded
+	Gzip bool
+}
+
+//ImportDataset uploads the csv data read from r into the given dataset without buffering it.
+//The data must not contain a header line. Requests with a streamed body are never retried,
+//wrap r in a bytes.Reader or strings.Reader if it should be.
+func (d *DomoAPI) ImportDataset(ctx context.Context, datasetID string, r io.Reader, opts ImportOptions) error {
+	if datasetID == "" {
+		return fmt.Errorf("error: missing datasetID")
+	}
+	method := "APPEND"
+	if opts.Replace {
+		method = "REPLACE"
+	} else {
+		//appending the same rows twice duplicates them
+		ctx = WithIdempotent(ctx, false)
+	}
+
+	body := r
+	if opts.Gzip {
+		zr := gzipReader(r)
+		//stops the compressing goroutine if the request ends before reading everything
+		defer zr.Close()
+		body = zr
+	}
+
+	req, err := d.newRequest(ctx, http.MethodPut, "/v1/datasets/"+datasetID+"/data?updateMethod="+method, body, "text/csv")
+	if err != nil {
+		return err
+	}
+	if opts.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return d.doJSON(req, nil, http.StatusNoContent)
+}
+
+//gzipReader compresses r on the fly. Closing the returned reader stops the compression.
+func gzipReader(r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		zw := gzip.NewWriter(pw)
+		_, err := io.Copy(zw, r)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
@@ -0,0 +1,122 @@
+package domoapi
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+func TestDomoAPI_ImportDataset(t *testing.T) {
+	data := "1,2,3\n4,5,6\n"
+
+	tests := []struct {
+		name           string
+		datasetID      string
+		opts           ImportOptions
+		wantMethod     string
+		wantIdempotent bool
+		wantErr        bool
+		api            func(ctrl *gomock.Controller, check func(req *http.Request)) *DomoAPI
+	}{
+		{
+			name:       "append plain csv",
+			datasetID:  "ds_id001",
+			wantMethod: "APPEND",
+			api: func(ctrl *gomock.Controller, check func(req *http.Request)) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					check(req)
+					return getMockResponse("", 204), nil
+				})
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+		{
+			name:           "replace gzip csv",
+			datasetID:      "ds_id001",
+			opts:           ImportOptions{Replace: true, Gzip: true},
+			wantMethod:     "REPLACE",
+			wantIdempotent: true,
+			api: func(ctrl *gomock.Controller, check func(req *http.Request)) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					check(req)
+					return getMockResponse("", 204), nil
+				})
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+		{
+			name:      "error response",
+			datasetID: "ds_id001",
+			wantErr:   true,
+			api: func(ctrl *gomock.Controller, check func(req *http.Request)) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 500), nil)
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+		{
+			name:    "missing dataset id",
+			wantErr: true,
+			api: func(ctrl *gomock.Controller, check func(req *http.Request)) *DomoAPI {
+				return &DomoAPI{
+					requestHandlerService: mocks.NewMockRequestHandlerService(ctrl),
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			check := func(req *http.Request) {
+				if req.Method != http.MethodPut || req.URL.Query().Get("updateMethod") != tt.wantMethod {
+					t.Errorf("request = %v %v, want PUT with updateMethod=%v", req.Method, req.URL, tt.wantMethod)
+				}
+				if IsIdempotent(req) != tt.wantIdempotent {
+					t.Errorf("IsIdempotent() = %v, want %v", IsIdempotent(req), tt.wantIdempotent)
+				}
+				var body io.Reader = req.Body
+				if tt.opts.Gzip {
+					if req.Header.Get("Content-Encoding") != "gzip" {
+						t.Errorf("Content-Encoding = %v, want gzip", req.Header.Get("Content-Encoding"))
+					}
+					zr, err := gzip.NewReader(req.Body)
+					if err != nil {
+						t.Fatalf("gzip.NewReader() error = %v", err)
+					}
+					body = zr
+				}
+				got, _ := ioutil.ReadAll(body)
+				if string(got) != data {
+					t.Errorf("request body = %q, want %q", got, data)
+				}
+			}
+			domoAPI := tt.api(ctrl, check)
+
+			err := domoAPI.ImportDataset(context.Background(), tt.datasetID, strings.NewReader(data), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DomoAPI.ImportDataset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
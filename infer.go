@@ -0,0 +1,228 @@
+package domoapi
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//DefaultInferSampleSize is the number of rows read by InferSchema when InferOptions.SampleSize is not set
+const DefaultInferSampleSize = 1000
+
+//maxDoubleDigits is the number of significant digits a DOUBLE keeps exactly, longer numbers are inferred as DECIMAL
+const maxDoubleDigits = 15
+
+//InferOptions configures InferSchema
+type InferOptions struct {
+	//SampleSize is the number of data rows read. Defaults to DefaultInferSampleSize
+	SampleSize int
+	//NoHeader tells that the first line is data. Columns are then named "column1", "column2", ...
+	NoHeader bool
+	//Comma is the field delimiter. Defaults to ','
+	Comma rune
+	//DateLayouts are the accepted layouts of DATE values. Defaults to yyyy-mm-dd
+	DateLayouts []string
+	//DateTimeLayouts are the accepted layouts of DATETIME values. Defaults to the layouts read by Rows
+	DateTimeLayouts []string
+}
+
+//InferReport describes how InferSchema came to its Schema
+type InferReport struct {
+	//Rows is the number of data rows sampled
+	Rows int
+	//Ambiguous lists the columns whose type should be checked by a human
+	Ambiguous []AmbiguousColumn
+}
+
+//AmbiguousColumn is a column InferSchema could not type with confidence
+type AmbiguousColumn struct {
+	Name string
+	//Type is the type put in the Schema
+	Type string
+	//Candidates are the types the sampled values would also fit
+	Candidates []string
+	Reason     string
+}
+
+//decimalNumber matches plain decimal numbers, leaving out hex, Inf and NaN which strconv also parses
+var decimalNumber = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+//columnStats counts the kinds of values seen in a column
+type columnStats struct {
+	empty, long, paddedLong, double, decimal, date, dateTime, text int
+}
+
+//InferSchema reads a csv sample from r and infers the domo type of each column:
+//LONG, DOUBLE, DECIMAL (numbers too precise for DOUBLE), DATE, DATETIME or STRING.
+//Columns which are empty in the sample, mix kinds of values or hold zero padded numbers are typed STRING
+//or the widest candidate and listed in the report.
+func InferSchema(r io.Reader, opts InferOptions) (*Schema, *InferReport, error) {
+	sampleSize := opts.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = DefaultInferSampleSize
+	}
+	dateFormats := opts.DateLayouts
+	if len(dateFormats) == 0 {
+		dateFormats = []string{dateLayout}
+	}
+	dateTimeFormats := opts.DateTimeLayouts
+	if len(dateTimeFormats) == 0 {
+		dateTimeFormats = dateTimeLayouts
+	}
+
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	first, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("error: csv sample is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, len(first))
+	for i := range first {
+		names[i] = fmt.Sprintf("column%d", i+1)
+		if !opts.NoHeader && strings.TrimSpace(first[i]) != "" {
+			names[i] = strings.TrimSpace(first[i])
+		}
+	}
+
+	stats := make([]columnStats, len(first))
+	report := &InferReport{}
+	record := first
+	if !opts.NoHeader {
+		record = nil
+	}
+	for report.Rows < sampleSize {
+		if record == nil {
+			record, err = cr.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		for i, value := range record {
+			stats[i].add(strings.TrimSpace(value), dateFormats, dateTimeFormats)
+		}
+		report.Rows++
+		record = nil
+	}
+
+	schema := &Schema{Columns: make([]Column, len(names))}
+	for i, name := range names {
+		columnType, ambiguous := stats[i].decide()
+		schema.Columns[i] = Column{Type: columnType, Name: name}
+		if ambiguous != nil {
+			ambiguous.Name = name
+			ambiguous.Type = columnType
+			report.Ambiguous = append(report.Ambiguous, *ambiguous)
+		}
+	}
+	return schema, report, nil
+}
+
+func (s *columnStats) add(value string, dateFormats, dateTimeFormats []string) {
+	switch {
+	case value == "":
+		s.empty++
+	case isLong(value):
+		s.long++
+		digits := strings.TrimLeft(value, "+-")
+		if len(digits) > 1 && digits[0] == '0' {
+			s.paddedLong++
+		}
+	case isDouble(value):
+		if significantDigits(value) > maxDoubleDigits {
+			s.decimal++
+		} else {
+			s.double++
+		}
+	case matchesLayout(value, dateFormats):
+		s.date++
+	case matchesLayout(value, dateTimeFormats):
+		s.dateTime++
+	default:
+		s.text++
+	}
+}
+
+//decide returns the column type and, when the choice is not clear cut, why
+func (s *columnStats) decide() (string, *AmbiguousColumn) {
+	numbers := s.long + s.double + s.decimal
+	dates := s.date + s.dateTime
+	values := numbers + dates + s.text
+
+	switch {
+	case values == 0:
+		return ColumnTypeString, &AmbiguousColumn{Reason: "no values in the sample"}
+	case s.text > 0:
+		if typed := values - s.text; typed > 0 {
+			candidate, _ := (&columnStats{long: s.long, double: s.double, decimal: s.decimal, date: s.date, dateTime: s.dateTime}).decide()
+			return ColumnTypeString, &AmbiguousColumn{
+				Candidates: []string{candidate},
+				Reason:     fmt.Sprintf("%d of %d values are not %s", s.text, values, candidate),
+			}
+		}
+		return ColumnTypeString, nil
+	case numbers > 0 && dates > 0:
+		return ColumnTypeString, &AmbiguousColumn{
+			Candidates: []string{ColumnTypeDouble, ColumnTypeDateTime},
+			Reason:     "mixes numbers and dates",
+		}
+	case s.paddedLong > 0 && s.double == 0 && s.decimal == 0:
+		return ColumnTypeString, &AmbiguousColumn{
+			Candidates: []string{ColumnTypeLong},
+			Reason:     fmt.Sprintf("%d values have leading zeros which LONG would drop", s.paddedLong),
+		}
+	case s.decimal > 0:
+		return ColumnTypeDecimal, nil
+	case s.double > 0:
+		return ColumnTypeDouble, nil
+	case s.long > 0:
+		return ColumnTypeLong, nil
+	case s.dateTime > 0 && s.date > 0:
+		return ColumnTypeDateTime, &AmbiguousColumn{
+			Candidates: []string{ColumnTypeDate},
+			Reason:     "mixes dates and datetimes",
+		}
+	case s.dateTime > 0:
+		return ColumnTypeDateTime, nil
+	}
+	return ColumnTypeDate, nil
+}
+
+func isLong(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+func isDouble(s string) bool {
+	return decimalNumber.MatchString(s)
+}
+
+//significantDigits counts the digits of a number without leading zeros and exponent
+func significantDigits(s string) int {
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		s = s[:i]
+	}
+	digits := strings.TrimLeft(strings.Replace(strings.TrimLeft(s, "+-"), ".", "", 1), "0")
+	return len(digits)
+}
+
+func matchesLayout(s string, layouts []string) bool {
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package domoapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	sample := `id,name,price,amount,day,updated,zip,note,mixed,stamp
+1,Euler,1.5,12345678901234567.89,2016-06-21,2016-06-21T17:20:36Z,01234,,1,2016-06-21
+2,Gauss,2,0.1,2016-06-22,2016-06-22 08:00:00,99999,,x,2016-06-21 10:00:00
+3,"Descartes, Rene",3.25,7,2016-06-23,2016-06-23T09:30:00,12345,,3,2016-06-22
+`
+	wantSchema := &Schema{Columns: []Column{
+		{Type: ColumnTypeLong, Name: "id"},
+		{Type: ColumnTypeString, Name: "name"},
+		{Type: ColumnTypeDouble, Name: "price"},
+		{Type: ColumnTypeDecimal, Name: "amount"},
+		{Type: ColumnTypeDate, Name: "day"},
+		{Type: ColumnTypeDateTime, Name: "updated"},
+		{Type: ColumnTypeString, Name: "zip"},
+		{Type: ColumnTypeString, Name: "note"},
+		{Type: ColumnTypeString, Name: "mixed"},
+		{Type: ColumnTypeDateTime, Name: "stamp"},
+	}}
+
+	schema, report, err := InferSchema(strings.NewReader(sample), InferOptions{})
+	if err != nil {
+		t.Fatalf("InferSchema() error = %v", err)
+	}
+	if !reflect.DeepEqual(schema, wantSchema) {
+		t.Errorf("InferSchema() = %v, want %v", schema, wantSchema)
+	}
+	if report.Rows != 3 {
+		t.Errorf("InferSchema() sampled %d rows, want 3", report.Rows)
+	}
+	var ambiguous []string
+	for _, a := range report.Ambiguous {
+		ambiguous = append(ambiguous, a.Name)
+	}
+	if want := []string{"zip", "note", "mixed", "stamp"}; !reflect.DeepEqual(ambiguous, want) {
+		t.Errorf("InferSchema() ambiguous columns = %v, want %v", ambiguous, want)
+	}
+	if c := report.Ambiguous[2]; !reflect.DeepEqual(c.Candidates, []string{ColumnTypeLong}) {
+		t.Errorf("InferSchema() mixed candidates = %v, want [LONG]", c.Candidates)
+	}
+}
+
+func TestInferSchema_Options(t *testing.T) {
+	sample := "21/06/2016;1\n22/06/2016;2\n23/06/2016;x\n"
+
+	schema, report, err := InferSchema(strings.NewReader(sample), InferOptions{
+		NoHeader:    true,
+		Comma:       ';',
+		SampleSize:  2,
+		DateLayouts: []string{"02/01/2006"},
+	})
+	if err != nil {
+		t.Fatalf("InferSchema() error = %v", err)
+	}
+	want := &Schema{Columns: []Column{
+		{Type: ColumnTypeDate, Name: "column1"},
+		{Type: ColumnTypeLong, Name: "column2"},
+	}}
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("InferSchema() = %v, want %v", schema, want)
+	}
+	if report.Rows != 2 || len(report.Ambiguous) != 0 {
+		t.Errorf("InferSchema() report = %+v", report)
+	}
+}
+
+func TestInferSchema_Empty(t *testing.T) {
+	if _, _, err := InferSchema(strings.NewReader(""), InferOptions{}); err == nil {
+		t.Errorf("InferSchema() on empty sample error = nil")
+	}
+}
@@ -0,0 +1,295 @@
+package domoapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//MaxPageSize is the largest number of datasets Domo returns per page
+const MaxPageSize = 50
+
+//Dataset sort fields, prefix a field with "-" to reverse the order
+const (
+	DatasetSortName          = "name"
+	DatasetSortLastTouched   = "lastTouched"
+	DatasetSortLastUpdated   = "lastUpdated"
+	DatasetSortCardCount     = "cardCount"
+	DatasetSortCardViewCount = "cardViewCount"
+)
+
+//ListDatasetsOptions filters and paginates dataset listings. The zero value lists all datasets sorted by name.
+type ListDatasetsOptions struct {
+	//Sort is the field to sort by, DatasetSortName by default
+	Sort string
+	//NameContains keeps datasets whose name contains the string, ignoring case
+	NameContains string
+	//OwnerID keeps datasets owned by the given user
+	OwnerID int64
+	//Offset skips the first datasets of the listing
+	Offset int
+	//Limit stops the listing after that many datasets, 0 lists all
+	Limit int
+	//PageSize is the number of datasets fetched per request, at most and by default MaxPageSize.
+	//A page with fewer datasets ends the listing.
+	PageSize int
+	//Concurrency is the number of pages fetched in parallel ahead of the iteration, 0 or 1 fetches one page at a time.
+	//Datasets are still returned in listing order; pages prefetched past the end or the Limit are discarded.
+	Concurrency int
+}
+
+func (o ListDatasetsOptions) pageSize() int {
+	if o.PageSize <= 0 || o.PageSize > MaxPageSize {
+		return MaxPageSize
+	}
+	return o.PageSize
+}
+
+//path returns the api path of the page starting at offset
+func (o ListDatasetsOptions) path(offset int) string {
+	sort := o.Sort
+	if sort == "" {
+		sort = DatasetSortName
+	}
+	q := "sort=" + url.QueryEscape(sort) + "&limit=" + strconv.Itoa(o.pageSize()) + "&offset=" + strconv.Itoa(offset)
+	if o.NameContains != "" {
+		q += "&nameLike=" + url.QueryEscape(o.NameContains)
+	}
+	return "/v1/datasets?" + q
+}
+
+//match applies the filters on the client too, for filters the api ignores
+func (o ListDatasetsOptions) match(ds DomoDataset) bool {
+	if o.NameContains != "" && !strings.Contains(strings.ToLower(ds.Name), strings.ToLower(o.NameContains)) {
+		return false
+	}
+	if o.OwnerID != 0 && (ds.Owner == nil || ds.Owner.ID != o.OwnerID) {
+		return false
+	}
+	return true
+}
+
+//DatasetIterator lazily walks a dataset listing, fetching the next page only when needed.
+//Callers must call Close when they are done, pages prefetched with Concurrency > 1 are only
+//cancelled by Close or by the end of the listing.
+//
+//	it := d.IterateDatasets(ctx, domoapi.ListDatasetsOptions{NameContains: "sales"})
+//	defer it.Close()
+//	for it.Next() {
+//		ds := it.Dataset()
+//		...
+//	}
+//	err := it.Err()
+type DatasetIterator struct {
+	d    *DomoAPI
+	ctx  context.Context
+	opts ListDatasetsOptions
+
+	page  []DomoDataset
+	next  int //offset of the next page to request
+	count int
+	last  bool
+	cur   DomoDataset
+	err   error
+
+	//pages requested ahead in listing order, when Concurrency > 1
+	pending []chan pageResult
+	cancel  context.CancelFunc
+}
+
+type pageResult struct {
+	datasets []DomoDataset
+	err      error
+}
+
+//IterateDatasets returns an iterator over the datasets selected by opts
+func (d *DomoAPI) IterateDatasets(ctx context.Context, opts ListDatasetsOptions) *DatasetIterator {
+	return &DatasetIterator{d: d, ctx: ctx, opts: opts, next: opts.Offset}
+}
+
+//Next advances to the next dataset, it returns false at the end of the listing or on error
+func (it *DatasetIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.opts.Limit > 0 && it.count >= it.opts.Limit {
+		it.stop()
+		return false
+	}
+	for {
+		for len(it.page) > 0 {
+			ds := it.page[0]
+			it.page = it.page[1:]
+			if it.opts.match(ds) {
+				it.cur = ds
+				it.count++
+				return true
+			}
+		}
+		if it.last {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+}
+
+//fetch reads the next page. Cancelling the context stops the pagination between pages.
+func (it *DatasetIterator) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err
+	}
+	var page []DomoDataset
+	var err error
+	if it.opts.Concurrency > 1 {
+		page, err = it.prefetched()
+	} else {
+		page, err = it.fetchPage(it.ctx, it.next)
+		it.next += it.opts.pageSize()
+	}
+	if err != nil {
+		it.stop()
+		return err
+	}
+	it.page = page
+	it.last = len(page) < it.opts.pageSize()
+	if it.last {
+		it.stop()
+	}
+	return nil
+}
+
+//prefetched keeps Concurrency pages in flight and returns the oldest one
+func (it *DatasetIterator) prefetched() ([]DomoDataset, error) {
+	if it.cancel == nil {
+		ctx, cancel := context.WithCancel(it.ctx)
+		it.cancel = cancel
+		it.ctx = ctx
+	}
+	for len(it.pending) < it.opts.Concurrency {
+		ch := make(chan pageResult, 1)
+		go func(ctx context.Context, offset int) {
+			datasets, err := it.fetchPage(ctx, offset)
+			ch <- pageResult{datasets: datasets, err: err}
+		}(it.ctx, it.next)
+		it.pending = append(it.pending, ch)
+		it.next += it.opts.pageSize()
+	}
+	r := <-it.pending[0]
+	it.pending = it.pending[1:]
+	return r.datasets, r.err
+}
+
+//stop cancels the pages still in flight and waits for them, so no request outlives the iteration
+func (it *DatasetIterator) stop() {
+	if it.cancel != nil {
+		it.cancel()
+	}
+	for _, ch := range it.pending {
+		<-ch
+	}
+	it.pending = nil
+}
+
+//Close stops the iteration and cancels the pages still in flight. It is safe to call it more than once.
+func (it *DatasetIterator) Close() error {
+	it.stop()
+	it.page = nil
+	it.last = true
+	return nil
+}
+
+func (it *DatasetIterator) fetchPage(ctx context.Context, offset int) ([]DomoDataset, error) {
+	req, err := it.d.newRequest(ctx, http.MethodGet, it.opts.path(offset), nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var page []DomoDataset
+	if err := it.d.doJSON(req, &page, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+//Dataset returns the current dataset
+func (it *DatasetIterator) Dataset() DomoDataset {
+	return it.cur
+}
+
+//Err returns the error which stopped the iteration, if any
+func (it *DatasetIterator) Err() error {
+	return it.err
+}
+
+//ListDatasetsWithOptions lists the datasets selected by opts, see IterateDatasets to walk large listings lazily
+func (d *DomoAPI) ListDatasetsWithOptions(ctx context.Context, opts ListDatasetsOptions) ([]DomoDataset, error) {
+	var datasets []DomoDataset
+	it := d.IterateDatasets(ctx, opts)
+	defer it.Close()
+	for it.Next() {
+		datasets = append(datasets, it.Dataset())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return datasets, nil
+}
+
+//NameMatch selects how FindDatasetIDs compares dataset names
+type NameMatch int
+
+//Name matching modes
+const (
+	MatchExact NameMatch = iota
+	MatchFold
+	MatchPrefix
+	MatchPrefixFold
+)
+
+func (m NameMatch) match(name, target string) bool {
+	switch m {
+	case MatchFold:
+		return strings.EqualFold(name, target)
+	case MatchPrefix:
+		return strings.HasPrefix(name, target)
+	case MatchPrefixFold:
+		return strings.HasPrefix(strings.ToLower(name), strings.ToLower(target))
+	}
+	return name == target
+}
+
+//FindDatasetOptions controls FindDatasetIDs
+type FindDatasetOptions struct {
+	//Match is the name comparison, MatchExact by default
+	Match NameMatch
+	//MaxResults stops the search after that many matches, 0 finds all
+	MaxResults int
+}
+
+//FindDatasetIDs returns the IDs of the datasets whose name matches name.
+//The name is also sent as filter to the api so only matching pages are fetched,
+//and the search stops as soon as MaxResults datasets are found.
+func (d *DomoAPI) FindDatasetIDs(ctx context.Context, name string, opts FindDatasetOptions) ([]string, error) {
+	var ids []string
+	it := d.IterateDatasets(ctx, ListDatasetsOptions{NameContains: name})
+	defer it.Close()
+	for it.Next() {
+		ds := it.Dataset()
+		if !opts.Match.match(ds.Name, name) {
+			continue
+		}
+		ids = append(ids, ds.ID)
+		if opts.MaxResults > 0 && len(ids) >= opts.MaxResults {
+			break
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,406 @@
+package domoapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+const ownedDatasetsJSON = `[
+	{"id": "ds1", "name": "Sales West", "owner": {"id": 27, "name": "DomoSupport"}},
+	{"id": "ds2", "name": "sales east", "owner": {"id": 12, "name": "Gauss"}},
+	{"id": "ds3", "name": "Marketing Sales", "owner": {"id": 27, "name": "DomoSupport"}},
+	{"id": "ds4", "name": "Sales West", "owner": {"id": 12, "name": "Gauss"}}
+]`
+
+func TestListDatasetsOptions_path(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   ListDatasetsOptions
+		offset int
+		want   string
+	}{
+		{
+			name: "defaults",
+			want: "/v1/datasets?sort=name&limit=50&offset=0",
+		},
+		{
+			name:   "sort, name and page size",
+			opts:   ListDatasetsOptions{Sort: "-" + DatasetSortLastUpdated, NameContains: "sales & ops", PageSize: 20},
+			offset: 40,
+			want:   "/v1/datasets?sort=-lastUpdated&limit=20&offset=40&nameLike=sales+%26+ops",
+		},
+		{
+			name: "page size above the maximum",
+			opts: ListDatasetsOptions{PageSize: 500},
+			want: "/v1/datasets?sort=name&limit=50&offset=0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.path(tt.offset); got != tt.want {
+				t.Errorf("ListDatasetsOptions.path() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fullPageJSON returns a page of n datasets
+func fullPageJSON(n int) string {
+	datasets := make([]DomoDataset, n)
+	for i := range datasets {
+		datasets[i] = DomoDataset{ID: fmt.Sprintf("ds%03d", i), Name: fmt.Sprintf("Dataset %03d", i)}
+	}
+	b, _ := json.Marshal(datasets)
+	return string(b)
+}
+
+// pagedAPI serves the datasets of ownedDatasetsJSON by limit and offset and records the requested urls
+func pagedAPI(ctrl *gomock.Controller, urls *[]string) *DomoAPI {
+	var all []DomoDataset
+	_ = json.Unmarshal([]byte(ownedDatasetsJSON), &all)
+
+	var mu sync.Mutex
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		*urls = append(*urls, req.URL.RequestURI())
+		mu.Unlock()
+		limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(req.URL.Query().Get("offset"))
+		page := []DomoDataset{}
+		for i := offset; i < offset+limit && i < len(all); i++ {
+			page = append(page, all[i])
+		}
+		b, _ := json.Marshal(page)
+		return getMockResponse(string(b), 200), nil
+	}).AnyTimes()
+	return &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+}
+
+func TestDomoAPI_ListDatasetsWithOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      ListDatasetsOptions
+		wantIDs   []string
+		wantCalls int
+	}{
+		{
+			name:      "all in a short page",
+			wantIDs:   []string{"ds1", "ds2", "ds3", "ds4"},
+			wantCalls: 1,
+		},
+		{
+			name:      "full pages need a last empty page",
+			opts:      ListDatasetsOptions{PageSize: 2},
+			wantIDs:   []string{"ds1", "ds2", "ds3", "ds4"},
+			wantCalls: 3,
+		},
+		{
+			name:      "short page ends the listing",
+			opts:      ListDatasetsOptions{PageSize: 3},
+			wantIDs:   []string{"ds1", "ds2", "ds3", "ds4"},
+			wantCalls: 2,
+		},
+		{
+			name:      "name contains ignoring case",
+			opts:      ListDatasetsOptions{NameContains: "SALES W"},
+			wantIDs:   []string{"ds1", "ds4"},
+			wantCalls: 1,
+		},
+		{
+			name:      "owner",
+			opts:      ListDatasetsOptions{OwnerID: 27},
+			wantIDs:   []string{"ds1", "ds3"},
+			wantCalls: 1,
+		},
+		{
+			name:      "limit stops without fetching more pages",
+			opts:      ListDatasetsOptions{Limit: 2, PageSize: 2},
+			wantIDs:   []string{"ds1", "ds2"},
+			wantCalls: 1,
+		},
+		{
+			name:    "concurrent pages keep the listing order",
+			opts:    ListDatasetsOptions{PageSize: 1, Concurrency: 3},
+			wantIDs: []string{"ds1", "ds2", "ds3", "ds4"},
+			//up to Concurrency-1 pages are requested past the empty one
+			wantCalls: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			var urls []string
+			domoAPI := pagedAPI(ctrl, &urls)
+
+			got, err := domoAPI.ListDatasetsWithOptions(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("DomoAPI.ListDatasetsWithOptions() error = %v", err)
+			}
+			var ids []string
+			for _, ds := range got {
+				ids = append(ids, ds.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("DomoAPI.ListDatasetsWithOptions() = %v, want %v", ids, tt.wantIDs)
+			}
+			if tt.wantCalls >= 0 && len(urls) != tt.wantCalls {
+				t.Errorf("requests = %v, want %d", urls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDomoAPI_IterateDatasets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	var urls []string
+	domoAPI := pagedAPI(ctrl, &urls)
+
+	it := domoAPI.IterateDatasets(context.Background(), ListDatasetsOptions{Offset: 1, PageSize: 3})
+	if len(urls) != 0 {
+		t.Errorf("IterateDatasets() fetched %v before Next", urls)
+	}
+	var n int
+	for it.Next() {
+		n++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("DatasetIterator.Err() = %v", err)
+	}
+	want := []string{"/v1/datasets?sort=name&limit=3&offset=1", "/v1/datasets?sort=name&limit=3&offset=4"}
+	if n != 3 || !reflect.DeepEqual(urls, want) {
+		t.Errorf("iterated %d datasets with requests %v, want 3 with %v", n, urls, want)
+	}
+	if it.Next() {
+		t.Errorf("DatasetIterator.Next() after the end = true")
+	}
+}
+
+func TestDomoAPI_IterateDatasets_error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 500), nil)
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	it := domoAPI.IterateDatasets(context.Background(), ListDatasetsOptions{})
+	if it.Next() {
+		t.Errorf("DatasetIterator.Next() = true, want false")
+	}
+	if it.Err() == nil {
+		t.Errorf("DatasetIterator.Err() = nil, want error")
+	}
+	if it.Next() {
+		t.Errorf("DatasetIterator.Next() after an error = true")
+	}
+}
+
+func TestDomoAPI_FindDatasetIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		find      string
+		opts      FindDatasetOptions
+		want      []string
+		wantCalls int
+	}{
+		{
+			name:      "exact",
+			find:      "Sales West",
+			want:      []string{"ds1", "ds4"},
+			wantCalls: 1,
+		},
+		{
+			name:      "exact is case sensitive",
+			find:      "Sales East",
+			wantCalls: 1,
+		},
+		{
+			name:      "fold",
+			find:      "Sales East",
+			opts:      FindDatasetOptions{Match: MatchFold},
+			want:      []string{"ds2"},
+			wantCalls: 1,
+		},
+		{
+			name:      "prefix",
+			find:      "Sales",
+			opts:      FindDatasetOptions{Match: MatchPrefix},
+			want:      []string{"ds1", "ds4"},
+			wantCalls: 1,
+		},
+		{
+			name:      "prefix ignoring case",
+			find:      "sales",
+			opts:      FindDatasetOptions{Match: MatchPrefixFold},
+			want:      []string{"ds1", "ds2", "ds4"},
+			wantCalls: 1,
+		},
+		{
+			name:      "first match stops the search",
+			find:      "Sales West",
+			opts:      FindDatasetOptions{MaxResults: 1},
+			want:      []string{"ds1"},
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			var urls []string
+			domoAPI := pagedAPI(ctrl, &urls)
+
+			got, err := domoAPI.FindDatasetIDs(context.Background(), tt.find, tt.opts)
+			if err != nil {
+				t.Fatalf("DomoAPI.FindDatasetIDs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DomoAPI.FindDatasetIDs() = %v, want %v", got, tt.want)
+			}
+			if len(urls) != tt.wantCalls {
+				t.Errorf("requests = %v, want %d", urls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDomoAPI_IterateDatasets_concurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const total, pageSize, concurrency = 95, 10, 3
+	all := make([]DomoDataset, total)
+	_ = json.Unmarshal([]byte(fullPageJSON(total)), &all)
+
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		//later pages answer first
+		offset, _ := strconv.Atoi(req.URL.Query().Get("offset"))
+		time.Sleep(time.Duration(total-offset) * 50 * time.Microsecond)
+		end := offset + pageSize
+		if end > total {
+			end = total
+		}
+		page := []DomoDataset{}
+		if offset < total {
+			page = all[offset:end]
+		}
+		b, _ := json.Marshal(page)
+		return getMockResponse(string(b), 200), nil
+	}).AnyTimes()
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	got, err := domoAPI.ListDatasetsWithOptions(context.Background(), ListDatasetsOptions{PageSize: pageSize, Concurrency: concurrency})
+	if err != nil {
+		t.Fatalf("DomoAPI.ListDatasetsWithOptions() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, all) {
+		t.Errorf("DomoAPI.ListDatasetsWithOptions() returned %d datasets out of order", len(got))
+	}
+	if maxInFlight > concurrency {
+		t.Errorf("%d pages in flight, want at most %d", maxInFlight, concurrency)
+	}
+}
+
+func TestDomoAPI_IterateDatasets_concurrentError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("offset") == "2" {
+			return getMockResponse(errorJSON, 500), nil
+		}
+		return getMockResponse(fullPageJSON(2), 200), nil
+	}).AnyTimes()
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	got, err := domoAPI.ListDatasetsWithOptions(context.Background(), ListDatasetsOptions{PageSize: 2, Concurrency: 4})
+	if err == nil || got != nil {
+		t.Errorf("DomoAPI.ListDatasetsWithOptions() = %v, %v, want error", got, err)
+	}
+}
+
+func TestDatasetIterator_Close(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var mu sync.Mutex
+	var started, finished int
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("offset") == "0" {
+			return getMockResponse(fullPageJSON(2), 200), nil
+		}
+		//prefetched pages only answer once cancelled
+		mu.Lock()
+		started++
+		mu.Unlock()
+		<-req.Context().Done()
+		mu.Lock()
+		finished++
+		mu.Unlock()
+		return nil, req.Context().Err()
+	}).AnyTimes()
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	it := domoAPI.IterateDatasets(context.Background(), ListDatasetsOptions{PageSize: 2, Concurrency: 3})
+	if !it.Next() {
+		t.Fatalf("DatasetIterator.Next() = false, err %v", it.Err())
+	}
+	if err := it.Close(); err != nil {
+		t.Errorf("DatasetIterator.Close() error = %v", err)
+	}
+	mu.Lock()
+	if finished != started {
+		t.Errorf("%d of %d prefetched requests still running after Close", started-finished, started)
+	}
+	mu.Unlock()
+	if it.Next() {
+		t.Errorf("DatasetIterator.Next() after Close = true")
+	}
+	if err := it.Close(); err != nil {
+		t.Errorf("second DatasetIterator.Close() error = %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package domoapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+//Page is a Domo page (dashboard). Children is only filled by ListPages, Locked is left unchanged by UpdatePage when nil.
+type Page struct {
+	ID            int64           `json:"id,omitempty"`
+	Name          string          `json:"name,omitempty"`
+	ParentID      int64           `json:"parentId,omitempty"`
+	OwnerID       int64           `json:"ownerId,omitempty"`
+	Locked        *bool           `json:"locked,omitempty"`
+	CollectionIDs []int64         `json:"collectionIds,omitempty"`
+	CardIDs       []int64         `json:"cardIds,omitempty"`
+	Visibility    *PageVisibility `json:"visibility,omitempty"`
+	Children      []Page          `json:"children,omitempty"`
+}
+
+//PageVisibility are the users and groups a page is shared with
+type PageVisibility struct {
+	UserIDs  []int64 `json:"userIds"`
+	GroupIDs []int64 `json:"groupIds"`
+}
+
+//PageCollection is a titled collection of cards on a page
+type PageCollection struct {
+	ID          int64   `json:"id,omitempty"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	CardIDs     []int64 `json:"cardIds,omitempty"`
+}
+
+//maxPagePageSize is the largest number of pages Domo returns per page of the listing
+const maxPagePageSize = 500
+
+func pagePath(pageID int64) string {
+	return "/v1/pages/" + strconv.FormatInt(pageID, 10)
+}
+
+func pageCollectionPath(pageID, collectionID int64) string {
+	return pagePath(pageID) + "/collections/" + strconv.FormatInt(collectionID, 10)
+}
+
+//ListPages lists all top level pages with their sub pages in Children
+func (d *DomoAPI) ListPages(ctx context.Context) ([]Page, error) {
+	var pages []Page
+	for offset := 0; ; offset += maxPagePageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		path := fmt.Sprintf("/v1/pages?limit=%d&offset=%d", maxPagePageSize, offset)
+		req, err := d.newRequest(ctx, http.MethodGet, path, nil, "application/json")
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Page
+		if err := d.doJSON(req, &page, http.StatusOK); err != nil {
+			return nil, err
+		}
+		pages = append(pages, page...)
+		if len(page) < maxPagePageSize {
+			return pages, nil
+		}
+	}
+}
+
+//GetPage gets a page by ID with its cards, collections and visibility
+func (d *DomoAPI) GetPage(ctx context.Context, pageID int64) (*Page, error) {
+	req, err := d.newRequest(ctx, http.MethodGet, pagePath(pageID), nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var p *Page
+	if err := d.doJSON(req, &p, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+//CreatePage creates a page, a sub page when ParentID is set, and returns it with its ID
+func (d *DomoAPI) CreatePage(ctx context.Context, page Page) (*Page, error) {
+	if page.Name == "" {
+		return nil, fmt.Errorf("error: missing page name")
+	}
+	page.ID = 0
+	page.Children = nil
+	req, err := d.newJSONRequest(WithIdempotent(ctx, false), http.MethodPost, "/v1/pages", page)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *Page
+	if err := d.doJSON(req, &p, http.StatusOK, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+//UpdatePage updates a page. Empty fields are left unchanged by Domo,
+//CardIDs and CollectionIDs replace the current ones when set.
+func (d *DomoAPI) UpdatePage(ctx context.Context, pageID int64, page Page) error {
+	page.ID = pageID
+	page.Children = nil
+	req, err := d.newJSONRequest(ctx, http.MethodPut, pagePath(pageID), page)
+	if err != nil {
+		return err
+	}
+	return d.doJSON(req, nil, http.StatusOK, http.StatusNoContent)
+}
+
+//SetPageVisibility shares a page with exactly the given users and groups
+func (d *DomoAPI) SetPageVisibility(ctx context.Context, pageID int64, visibility PageVisibility) error {
+	//empty lists are sent as [] so that they unshare the page instead of being ignored
+	if visibility.UserIDs == nil {
+		visibility.UserIDs = []int64{}
+	}
+	if visibility.GroupIDs == nil {
+		visibility.GroupIDs = []int64{}
+	}
+	return d.UpdatePage(ctx, pageID, Page{Visibility: &visibility})
+}
+
+//DeletePage deletes a page, its cards are kept
+func (d *DomoAPI) DeletePage(ctx context.Context, pageID int64) error {
+	req, err := d.newRequest(ctx, http.MethodDelete, pagePath(pageID), nil, "")
+	if err != nil {
+		return err
+	}
+	return d.doJSON(req, nil, http.StatusNoContent)
+}
+
+//ListPageCollections lists the collections of a page
+func (d *DomoAPI) ListPageCollections(ctx context.Context, pageID int64) ([]PageCollection, error) {
+	req, err := d.newRequest(ctx, http.MethodGet, pagePath(pageID)+"/collections", nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var collections []PageCollection
+	if err := d.doJSON(req, &collections, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+//CreatePageCollection adds a collection to a page
+func (d *DomoAPI) CreatePageCollection(ctx context.Context, pageID int64, collection PageCollection) error {
+	if collection.Title == "" {
+		return fmt.Errorf("error: missing page collection title")
+	}
+	collection.ID = 0
+	req, err := d.newJSONRequest(WithIdempotent(ctx, false), http.MethodPost, pagePath(pageID)+"/collections", collection)
+	if err != nil {
+		return err
+	}
+	return d.doJSON(req, nil, http.StatusOK, http.StatusCreated, http.StatusNoContent)
+}
+
+//UpdatePageCollection updates a collection of a page, CardIDs replaces its cards when set
+func (d *DomoAPI) UpdatePageCollection(ctx context.Context, pageID, collectionID int64, collection PageCollection) error {
+	collection.ID = collectionID
+	req, err := d.newJSONRequest(ctx, http.MethodPut, pageCollectionPath(pageID, collectionID), collection)
+	if err != nil {
+		return err
+	}
+	return d.doJSON(req, nil, http.StatusOK, http.StatusNoContent)
+}
+
+//SetPageCollectionCards replaces the cards of a collection, an empty list removes them all
+func (d *DomoAPI) SetPageCollectionCards(ctx context.Context, pageID, collectionID int64, cardIDs []int64) error {
+	body := struct {
+		ID      int64   `json:"id"`
+		CardIDs []int64 `json:"cardIds"`
+	}{ID: collectionID, CardIDs: cardIDs}
+	if body.CardIDs == nil {
+		body.CardIDs = []int64{}
+	}
+	req, err := d.newJSONRequest(ctx, http.MethodPut, pageCollectionPath(pageID, collectionID), body)
+	if err != nil {
+		return err
+	}
+	return d.doJSON(req, nil, http.StatusOK, http.StatusNoContent)
+}
+
+//DeletePageCollection deletes a collection of a page, its cards stay on the page
+func (d *DomoAPI) DeletePageCollection(ctx context.Context, pageID, collectionID int64) error {
+	req, err := d.newRequest(ctx, http.MethodDelete, pageCollectionPath(pageID, collectionID), nil, "")
+	if err != nil {
+		return err
+	}
+	return d.doJSON(req, nil, http.StatusNoContent)
+}
@@ -0,0 +1,178 @@
+package domoapi
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+const pageJSON = `{
+	"id": 384540563,
+	"name": "Parties",
+	"parentId": 0,
+	"ownerId": 27,
+	"locked": false,
+	"collectionIds": [1],
+	"cardIds": [412, 413],
+	"visibility": { "userIds": [27], "groupIds": [3] }
+}`
+
+const listPagesJSON = `[{
+	"id": 384540563,
+	"name": "Parties",
+	"children": [ { "id": 384540564, "name": "Guests", "children": [] } ]
+}]`
+
+func TestDomoAPI_pageMethods(t *testing.T) {
+	//noError runs the page or collection methods returning only an error
+	noError := func(name string, call func(d *DomoAPI) error) func(t *testing.T, d *DomoAPI) {
+		return func(t *testing.T, d *DomoAPI) {
+			if err := call(d); err != nil {
+				t.Errorf("DomoAPI.%s() error = %v", name, err)
+			}
+		}
+	}
+	tests := []struct {
+		name    string
+		request mockedRequest
+		run     func(t *testing.T, d *DomoAPI)
+	}{
+		{
+			name:    "list",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/pages?limit=500&offset=0", response: listPagesJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.ListPages(context.Background())
+				if err != nil {
+					t.Fatalf("DomoAPI.ListPages() error = %v", err)
+				}
+				if len(got) != 1 || got[0].ID != 384540563 || len(got[0].Children) != 1 || got[0].Children[0].ID != 384540564 || got[0].Children[0].Name != "Guests" {
+					t.Errorf("DomoAPI.ListPages() = %+v", got)
+				}
+			},
+		},
+		{
+			name:    "get",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/pages/384540563", response: pageJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.GetPage(context.Background(), 384540563)
+				if err != nil {
+					t.Fatalf("DomoAPI.GetPage() error = %v", err)
+				}
+				if got.ID != 384540563 || got.OwnerID != 27 || got.Locked == nil || *got.Locked || !reflect.DeepEqual(got.CardIDs, []int64{412, 413}) ||
+					got.Visibility == nil || !reflect.DeepEqual(got.Visibility.UserIDs, []int64{27}) || !reflect.DeepEqual(got.Visibility.GroupIDs, []int64{3}) {
+					t.Errorf("DomoAPI.GetPage() = %+v", got)
+				}
+			},
+		},
+		{
+			name:    "get not found",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/pages/1", response: notFoundJSON, statusCode: 404},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.GetPage(context.Background(), 1)
+				if !IsNotFound(err) || got != nil {
+					t.Errorf("DomoAPI.GetPage() = %v, %v, want not found", got, err)
+				}
+			},
+		},
+		{
+			name:    "create",
+			request: mockedRequest{method: http.MethodPost, uri: "/v1/pages", body: `{"name":"Parties","cardIds":[412]}`, response: pageJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.CreatePage(context.Background(), Page{Name: "Parties", CardIDs: []int64{412}})
+				if err != nil {
+					t.Fatalf("DomoAPI.CreatePage() error = %v", err)
+				}
+				if got.ID != 384540563 || got.Name != "Parties" {
+					t.Errorf("DomoAPI.CreatePage() = %+v", got)
+				}
+			},
+		},
+		{
+			name:    "update",
+			request: mockedRequest{method: http.MethodPut, uri: "/v1/pages/384540563", body: `{"id":384540563,"name":"Parties 2020","locked":true}`, statusCode: 200},
+			run: noError("UpdatePage", func(d *DomoAPI) error {
+				return d.UpdatePage(context.Background(), 384540563, Page{Name: "Parties 2020", Locked: Bool(true)})
+			}),
+		},
+		{
+			name:    "unlock",
+			request: mockedRequest{method: http.MethodPut, uri: "/v1/pages/384540563", body: `{"id":384540563,"locked":false}`, statusCode: 200},
+			run: noError("UpdatePage", func(d *DomoAPI) error {
+				return d.UpdatePage(context.Background(), 384540563, Page{Locked: Bool(false)})
+			}),
+		},
+		{
+			name:    "unshare",
+			request: mockedRequest{method: http.MethodPut, uri: "/v1/pages/384540563", body: `{"id":384540563,"visibility":{"userIds":[],"groupIds":[3]}}`, statusCode: 200},
+			run: noError("SetPageVisibility", func(d *DomoAPI) error {
+				return d.SetPageVisibility(context.Background(), 384540563, PageVisibility{GroupIDs: []int64{3}})
+			}),
+		},
+		{
+			name:    "delete",
+			request: mockedRequest{method: http.MethodDelete, uri: "/v1/pages/384540563", statusCode: 204},
+			run: noError("DeletePage", func(d *DomoAPI) error {
+				return d.DeletePage(context.Background(), 384540563)
+			}),
+		},
+		{
+			name:    "list collections",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/pages/384540563/collections", response: `[{"id": 1, "title": "Guests", "cardIds": [412]}]`, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.ListPageCollections(context.Background(), 384540563)
+				if err != nil {
+					t.Fatalf("DomoAPI.ListPageCollections() error = %v", err)
+				}
+				if want := []PageCollection{{ID: 1, Title: "Guests", CardIDs: []int64{412}}}; !reflect.DeepEqual(got, want) {
+					t.Errorf("DomoAPI.ListPageCollections() = %+v, want %+v", got, want)
+				}
+			},
+		},
+		{
+			name:    "create collection",
+			request: mockedRequest{method: http.MethodPost, uri: "/v1/pages/384540563/collections", body: `{"title":"Guests","cardIds":[412]}`, statusCode: 200},
+			run: noError("CreatePageCollection", func(d *DomoAPI) error {
+				return d.CreatePageCollection(context.Background(), 384540563, PageCollection{Title: "Guests", CardIDs: []int64{412}})
+			}),
+		},
+		{
+			name:    "update collection",
+			request: mockedRequest{method: http.MethodPut, uri: "/v1/pages/384540563/collections/1", body: `{"id":1,"description":"Who comes"}`, statusCode: 200},
+			run: noError("UpdatePageCollection", func(d *DomoAPI) error {
+				return d.UpdatePageCollection(context.Background(), 384540563, 1, PageCollection{Description: "Who comes"})
+			}),
+		},
+		{
+			name:    "remove collection cards",
+			request: mockedRequest{method: http.MethodPut, uri: "/v1/pages/384540563/collections/1", body: `{"id":1,"cardIds":[]}`, statusCode: 200},
+			run: noError("SetPageCollectionCards", func(d *DomoAPI) error {
+				return d.SetPageCollectionCards(context.Background(), 384540563, 1, nil)
+			}),
+		},
+		{
+			name:    "delete collection",
+			request: mockedRequest{method: http.MethodDelete, uri: "/v1/pages/384540563/collections/1", statusCode: 204},
+			run: noError("DeletePageCollection", func(d *DomoAPI) error {
+				return d.DeletePageCollection(context.Background(), 384540563, 1)
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tt.run(t, newMockedDomoAPI(t, ctrl, tt.request))
+		})
+	}
+}
+
+func TestDomoAPI_CreatePage_missingName(t *testing.T) {
+	domoAPI := &DomoAPI{tokens: &tokenSource{token: &sampleToken}}
+	if _, err := domoAPI.CreatePage(context.Background(), Page{CardIDs: []int64{412}}); err == nil {
+		t.Errorf("DomoAPI.CreatePage() without a name should fail")
+	}
+}
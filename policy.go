@@ -0,0 +1,307 @@
+package domoapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+//Policy is a Personalized Data Permission (PDP) policy of a dataset.
+//Users, groups and virtual users see only the rows matching all filters of their policies.
+type Policy struct {
+	ID           int64          `json:"id,omitempty"`
+	Type         string         `json:"type,omitempty"`
+	Name         string         `json:"name,omitempty"`
+	Filters      []PolicyFilter `json:"filters"`
+	Users        []int64        `json:"users"`
+	VirtualUsers []string       `json:"virtualUsers"`
+	Groups       []int64        `json:"groups"`
+}
+
+//PolicyFilter limits the rows of a column, Not negates the filter
+type PolicyFilter struct {
+	Column   string   `json:"column"`
+	Values   []string `json:"values"`
+	Operator string   `json:"operator"`
+	Not      bool     `json:"not"`
+}
+
+//Policy types. Open policies give access to all rows and system policies are managed by Domo.
+const (
+	PolicyTypeUser   = "user"
+	PolicyTypeOpen   = "open"
+	PolicyTypeSystem = "system"
+)
+
+//Policy filter operators
+const (
+	PolicyOperatorEquals           = "EQUALS"
+	PolicyOperatorLike             = "LIKE"
+	PolicyOperatorGreaterThan      = "GREATER_THAN"
+	PolicyOperatorLessThan         = "LESS_THAN"
+	PolicyOperatorGreaterThanEqual = "GREATER_THAN_EQUAL"
+	PolicyOperatorLessThanEqual    = "LESS_THAN_EQUAL"
+	PolicyOperatorBetween          = "BETWEEN"
+	PolicyOperatorBeginsWith       = "BEGINS_WITH"
+	PolicyOperatorEndsWith         = "ENDS_WITH"
+	PolicyOperatorContains         = "CONTAINS"
+)
+
+//withLists returns p with empty instead of nil lists, so they are sent as [] rather than null
+func (p Policy) withLists() Policy {
+	if p.Filters == nil {
+		p.Filters = []PolicyFilter{}
+	}
+	if p.Users == nil {
+		p.Users = []int64{}
+	}
+	if p.VirtualUsers == nil {
+		p.VirtualUsers = []string{}
+	}
+	if p.Groups == nil {
+		p.Groups = []int64{}
+	}
+	return p
+}
+
+func policiesPath(datasetID string) string {
+	return "/v1/datasets/" + datasetID + "/policies"
+}
+
+func policyPath(datasetID string, policyID int64) string {
+	return policiesPath(datasetID) + "/" + strconv.FormatInt(policyID, 10)
+}
+
+//ListPolicies lists the PDP policies of a dataset
+func (d *DomoAPI) ListPolicies(ctx context.Context, datasetID string) ([]Policy, error) {
+	if datasetID == "" {
+		return nil, fmt.Errorf("error: missing datasetID")
+	}
+	req, err := d.newRequest(ctx, http.MethodGet, policiesPath(datasetID), nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []Policy
+	if err := d.doJSON(req, &policies, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+//GetPolicy gets a PDP policy of a dataset
+func (d *DomoAPI) GetPolicy(ctx context.Context, datasetID string, policyID int64) (*Policy, error) {
+	if datasetID == "" {
+		return nil, fmt.Errorf("error: missing datasetID")
+	}
+	req, err := d.newRequest(ctx, http.MethodGet, policyPath(datasetID, policyID), nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var p *Policy
+	if err := d.doJSON(req, &p, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+//CreatePolicy adds a PDP policy to a dataset and returns it with its ID
+func (d *DomoAPI) CreatePolicy(ctx context.Context, datasetID string, policy Policy) (*Policy, error) {
+	if datasetID == "" {
+		return nil, fmt.Errorf("error: missing datasetID")
+	}
+	policy = policy.withLists()
+	policy.ID = 0
+	req, err := d.newJSONRequest(WithIdempotent(ctx, false), http.MethodPost, policiesPath(datasetID), policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *Policy
+	if err := d.doJSON(req, &p, http.StatusOK, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+//UpdatePolicy replaces a PDP policy of a dataset and returns the updated policy
+func (d *DomoAPI) UpdatePolicy(ctx context.Context, datasetID string, policyID int64, policy Policy) (*Policy, error) {
+	if datasetID == "" {
+		return nil, fmt.Errorf("error: missing datasetID")
+	}
+	policy = policy.withLists()
+	policy.ID = policyID
+	req, err := d.newJSONRequest(ctx, http.MethodPut, policyPath(datasetID, policyID), policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *Policy
+	if err := d.doJSON(req, &p, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+//DeletePolicy removes a PDP policy from a dataset
+func (d *DomoAPI) DeletePolicy(ctx context.Context, datasetID string, policyID int64) error {
+	if datasetID == "" {
+		return fmt.Errorf("error: missing datasetID")
+	}
+	req, err := d.newRequest(ctx, http.MethodDelete, policyPath(datasetID, policyID), nil, "")
+	if err != nil {
+		return err
+	}
+	return d.doJSON(req, nil, http.StatusNoContent)
+}
+
+//PolicyChanges are the changes made, or planned with DryRun, by SyncPolicies
+type PolicyChanges struct {
+	Created []Policy
+	Updated []Policy
+	Deleted []Policy
+}
+
+//Empty reports whether the policies were already in the desired state
+func (c *PolicyChanges) Empty() bool {
+	return len(c.Created)+len(c.Updated)+len(c.Deleted) == 0
+}
+
+//PolicySyncOptions controls SyncPolicies
+type PolicySyncOptions struct {
+	//KeepUnlisted leaves policies which are not in the desired state instead of deleting them
+	KeepUnlisted bool
+	//DryRun only computes the changes without applying them
+	DryRun bool
+}
+
+//SyncPolicies brings the PDP policies of a dataset to the desired state with the fewest calls.
+//Policies are matched by name: missing ones are created, differing ones updated and unlisted ones deleted.
+//Filters, users, groups and virtual users are compared regardless of order.
+//Open and system policies managed by Domo, like "All Rows", are never deleted.
+//On error the returned changes hold what was applied so far.
+func (d *DomoAPI) SyncPolicies(ctx context.Context, datasetID string, desired []Policy, opts PolicySyncOptions) (*PolicyChanges, error) {
+	current, err := d.ListPolicies(ctx, datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Policy, len(current))
+	for _, p := range current {
+		byName[p.Name] = p
+	}
+	seen := make(map[string]bool, len(desired))
+	plan := &PolicyChanges{}
+	for _, want := range desired {
+		if want.Name == "" {
+			return nil, fmt.Errorf("error: desired policies need a name")
+		}
+		if seen[want.Name] {
+			return nil, fmt.Errorf("error: duplicate desired policy %q", want.Name)
+		}
+		seen[want.Name] = true
+		if want.Type == "" {
+			want.Type = PolicyTypeUser
+		}
+		have, ok := byName[want.Name]
+		if !ok {
+			plan.Created = append(plan.Created, want)
+			continue
+		}
+		if !samePolicy(have, want) {
+			want.ID = have.ID
+			plan.Updated = append(plan.Updated, want)
+		}
+	}
+	if !opts.KeepUnlisted {
+		for _, p := range current {
+			if !seen[p.Name] && p.Type != PolicyTypeOpen && p.Type != PolicyTypeSystem {
+				plan.Deleted = append(plan.Deleted, p)
+			}
+		}
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	done := &PolicyChanges{}
+	for _, p := range plan.Created {
+		created, err := d.CreatePolicy(ctx, datasetID, p)
+		if err != nil {
+			return done, err
+		}
+		done.Created = append(done.Created, *created)
+	}
+	for _, p := range plan.Updated {
+		updated, err := d.UpdatePolicy(ctx, datasetID, p.ID, p)
+		if err != nil {
+			return done, err
+		}
+		done.Updated = append(done.Updated, *updated)
+	}
+	for _, p := range plan.Deleted {
+		if err := d.DeletePolicy(ctx, datasetID, p.ID); err != nil {
+			return done, err
+		}
+		done.Deleted = append(done.Deleted, p)
+	}
+	return done, nil
+}
+
+//samePolicy compares two policies ignoring their ID and the order of their lists
+func samePolicy(a, b Policy) bool {
+	if a.Type != b.Type || len(a.Filters) != len(b.Filters) {
+		return false
+	}
+	if !sameIDs(a.Users, b.Users) || !sameIDs(a.Groups, b.Groups) || !sameStrings(a.VirtualUsers, b.VirtualUsers) {
+		return false
+	}
+	af, bf := filterKeys(a.Filters), filterKeys(b.Filters)
+	return sameStrings(af, bf)
+}
+
+func filterKeys(filters []PolicyFilter) []string {
+	keys := make([]string, len(filters))
+	for i, f := range filters {
+		values := append([]string(nil), f.Values...)
+		//the bounds of BETWEEN are ordered
+		if f.Operator != PolicyOperatorBetween {
+			sort.Strings(values)
+		}
+		keys[i] = fmt.Sprintf("%q %q %v %q", f.Column, f.Operator, f.Not, values)
+	}
+	return keys
+}
+
+func sameIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]int64(nil), a...), append([]int64(nil), b...)
+	sort.Slice(a, func(i, j int) bool { return a[i] < a[j] })
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,263 @@
+package domoapi
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+const policiesJSON = `[ {
+	"id": 1,
+	"type": "open",
+	"name": "All Rows",
+	"filters": [],
+	"users": [],
+	"virtualUsers": [],
+	"groups": []
+  }, {
+	"id": 8,
+	"type": "user",
+	"name": "West",
+	"filters": [ {
+	  "column": "Region",
+	  "values": [ "West", "Pacific" ],
+	  "operator": "EQUALS",
+	  "not": false
+	} ],
+	"users": [ 27, 12 ],
+	"virtualUsers": [],
+	"groups": [ 3 ]
+  }, {
+	"id": 9,
+	"type": "user",
+	"name": "East",
+	"filters": [ {
+	  "column": "Region",
+	  "values": [ "East" ],
+	  "operator": "EQUALS",
+	  "not": false
+	} ],
+	"users": [ 5 ],
+	"virtualUsers": [],
+	"groups": []
+  } ]`
+
+func TestDomoAPI_policyMethods(t *testing.T) {
+	west := Policy{
+		Type:    PolicyTypeUser,
+		Name:    "West",
+		Filters: []PolicyFilter{{Column: "Region", Values: []string{"West"}, Operator: PolicyOperatorEquals}},
+		Users:   []int64{27},
+	}
+	//lists are sent as [] rather than null
+	westJSON := `"type":"user","name":"West","filters":[{"column":"Region","values":["West"],"operator":"EQUALS","not":false}],"users":[27],"virtualUsers":[],"groups":[]}`
+	tests := []struct {
+		name    string
+		request mockedRequest
+		run     func(t *testing.T, d *DomoAPI)
+	}{
+		{
+			name:    "list",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/datasets/ds_id001/policies", response: policiesJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.ListPolicies(context.Background(), "ds_id001")
+				if err != nil {
+					t.Fatalf("DomoAPI.ListPolicies() error = %v", err)
+				}
+				if len(got) != 3 || got[1].ID != 8 || !reflect.DeepEqual(got[1].Users, []int64{27, 12}) || !reflect.DeepEqual(got[1].Groups, []int64{3}) ||
+					len(got[1].Filters) != 1 || !reflect.DeepEqual(got[1].Filters[0].Values, []string{"West", "Pacific"}) {
+					t.Errorf("DomoAPI.ListPolicies() = %+v", got)
+				}
+			},
+		},
+		{
+			name:    "get",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/datasets/ds_id001/policies/8", response: `{"id": 8, "type": "user", "name": "West"}`, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.GetPolicy(context.Background(), "ds_id001", 8)
+				if err != nil {
+					t.Fatalf("DomoAPI.GetPolicy() error = %v", err)
+				}
+				if got.ID != 8 || got.Type != PolicyTypeUser || got.Name != "West" {
+					t.Errorf("DomoAPI.GetPolicy() = %+v", got)
+				}
+			},
+		},
+		{
+			name:    "get not found",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/datasets/ds_id001/policies/80", response: notFoundJSON, statusCode: 404},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.GetPolicy(context.Background(), "ds_id001", 80)
+				if !IsNotFound(err) || got != nil {
+					t.Errorf("DomoAPI.GetPolicy() = %v, %v, want not found", got, err)
+				}
+			},
+		},
+		{
+			name: "create",
+			request: mockedRequest{
+				method:     http.MethodPost,
+				uri:        "/v1/datasets/ds_id001/policies",
+				body:       "{" + westJSON,
+				response:   `{"id": 10, "type": "user", "name": "West"}`,
+				statusCode: 200,
+			},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.CreatePolicy(context.Background(), "ds_id001", west)
+				if err != nil {
+					t.Fatalf("DomoAPI.CreatePolicy() error = %v", err)
+				}
+				if got.ID != 10 {
+					t.Errorf("DomoAPI.CreatePolicy() = %+v", got)
+				}
+			},
+		},
+		{
+			name: "update",
+			request: mockedRequest{
+				method:     http.MethodPut,
+				uri:        "/v1/datasets/ds_id001/policies/8",
+				body:       `{"id":8,` + westJSON,
+				response:   `{"id": 8, "type": "user", "name": "West"}`,
+				statusCode: 200,
+			},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.UpdatePolicy(context.Background(), "ds_id001", 8, west)
+				if err != nil {
+					t.Fatalf("DomoAPI.UpdatePolicy() error = %v", err)
+				}
+				if got.ID != 8 {
+					t.Errorf("DomoAPI.UpdatePolicy() = %+v", got)
+				}
+			},
+		},
+		{
+			name:    "delete",
+			request: mockedRequest{method: http.MethodDelete, uri: "/v1/datasets/ds_id001/policies/8", statusCode: 204},
+			run: func(t *testing.T, d *DomoAPI) {
+				if err := d.DeletePolicy(context.Background(), "ds_id001", 8); err != nil {
+					t.Errorf("DomoAPI.DeletePolicy() error = %v", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tt.run(t, newMockedDomoAPI(t, ctrl, tt.request))
+		})
+	}
+}
+
+func TestDomoAPI_SyncPolicies(t *testing.T) {
+	desired := []Policy{
+		{
+			//same as the live policy apart from order
+			Name:    "West",
+			Filters: []PolicyFilter{{Column: "Region", Values: []string{"Pacific", "West"}, Operator: PolicyOperatorEquals}},
+			Users:   []int64{12, 27},
+			Groups:  []int64{3},
+		},
+		{
+			Name:    "East",
+			Filters: []PolicyFilter{{Column: "Region", Values: []string{"East"}, Operator: PolicyOperatorEquals}},
+			Users:   []int64{5, 6},
+		},
+		{
+			Name:    "North",
+			Filters: []PolicyFilter{{Column: "Region", Values: []string{"North"}, Operator: PolicyOperatorEquals}},
+			Groups:  []int64{4},
+		},
+	}
+	tests := []struct {
+		name      string
+		desired   []Policy
+		opts      PolicySyncOptions
+		wantCalls []string
+		wantErr   bool
+	}{
+		{
+			name:      "minimal changes",
+			desired:   desired,
+			wantCalls: []string{"GET /v1/datasets/ds_id001/policies", "POST /v1/datasets/ds_id001/policies", "PUT /v1/datasets/ds_id001/policies/9"},
+		},
+		{
+			name:      "unlisted policies are deleted except open ones",
+			desired:   desired[:1],
+			wantCalls: []string{"GET /v1/datasets/ds_id001/policies", "DELETE /v1/datasets/ds_id001/policies/9"},
+		},
+		{
+			name:      "unlisted policies are kept",
+			desired:   desired[:1],
+			opts:      PolicySyncOptions{KeepUnlisted: true},
+			wantCalls: []string{"GET /v1/datasets/ds_id001/policies"},
+		},
+		{
+			name:      "dry run",
+			desired:   desired,
+			opts:      PolicySyncOptions{DryRun: true},
+			wantCalls: []string{"GET /v1/datasets/ds_id001/policies"},
+		},
+		{
+			name:      "duplicate names",
+			desired:   []Policy{desired[0], desired[0]},
+			wantCalls: []string{"GET /v1/datasets/ds_id001/policies"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var calls []string
+			rmock := mocks.NewMockRequestHandlerService(ctrl)
+			rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, req.Method+" "+req.URL.Path)
+				switch req.Method {
+				case http.MethodGet:
+					return getMockResponse(policiesJSON, 200), nil
+				case http.MethodDelete:
+					return getMockResponse("", 204), nil
+				}
+				body, _ := ioutil.ReadAll(req.Body)
+				return getMockResponse(string(body), 200), nil
+			}).AnyTimes()
+			domoAPI := &DomoAPI{
+				requestHandlerService: rmock,
+				tokens:                &tokenSource{token: &sampleToken},
+			}
+
+			changes, err := domoAPI.SyncPolicies(context.Background(), "ds_id001", tt.desired, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DomoAPI.SyncPolicies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if tt.opts.DryRun {
+				if len(changes.Created) != 1 || len(changes.Updated) != 1 || changes.Updated[0].ID != 9 || len(changes.Deleted) != 0 {
+					t.Errorf("DomoAPI.SyncPolicies() = %+v", changes)
+				}
+			}
+		})
+	}
+}
+
+func Test_samePolicy(t *testing.T) {
+	between := Policy{Filters: []PolicyFilter{{Column: "Amount", Values: []string{"10", "2"}, Operator: PolicyOperatorBetween}}}
+	swapped := Policy{Filters: []PolicyFilter{{Column: "Amount", Values: []string{"2", "10"}, Operator: PolicyOperatorBetween}}}
+	if samePolicy(between, swapped) {
+		t.Errorf("samePolicy() should keep the order of BETWEEN bounds")
+	}
+	if !samePolicy(between, between) {
+		t.Errorf("samePolicy() of equal policies = false")
+	}
+}
@@ -0,0 +1,250 @@
+package domoapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+//QueryResult is the result of a sql query against a dataset.
+//Rows hold values decoded by column type like Rows.Values.
+type QueryResult struct {
+	DatasetID  string
+	Columns    []Column
+	Metadata   []ColumnMetadata
+	Rows       [][]interface{}
+	NumRows    int
+	NumColumns int
+	FromCache  bool
+}
+
+//ColumnMetadata describes a column of a query result
+type ColumnMetadata struct {
+	Type        string `json:"type"`
+	DatasetID   string `json:"dataSourceId"`
+	MaxLength   int    `json:"maxLength"`
+	MinLength   int    `json:"minLength"`
+	PeriodIndex int    `json:"periodIndex"`
+}
+
+type queryRequest struct {
+	SQL string `json:"sql"`
+}
+
+//QueryDataset runs a sql query against a dataset on the server and returns the whole result.
+//The dataset is referred to as "table" in the query, e.g. "SELECT Friend, COUNT(*) FROM table GROUP BY Friend".
+//Use QueryRows for large results.
+func (d *DomoAPI) QueryDataset(ctx context.Context, datasetID string, sql string) (*QueryResult, error) {
+	rows, err := d.QueryRows(ctx, datasetID, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	src := rows.src.(*queryRowSource)
+	result := &QueryResult{
+		DatasetID: src.datasetID,
+		Columns:   rows.Columns(),
+		Metadata:  src.metadata,
+	}
+	for rows.Next() {
+		result.Rows = append(result.Rows, rows.Values())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	result.NumRows = src.numRows
+	result.NumColumns = src.numColumns
+	result.FromCache = src.fromCache
+	return result, nil
+}
+
+//QueryRows runs a sql query against a dataset like QueryDataset and decodes the result rows one at a time
+//while they are read from the response. Column types come from the result metadata.
+//The caller must close the returned Rows.
+func (d *DomoAPI) QueryRows(ctx context.Context, datasetID string, sql string) (*Rows, error) {
+	if datasetID == "" {
+		return nil, fmt.Errorf("error: missing datasetID")
+	}
+	if sql == "" {
+		return nil, fmt.Errorf("error: missing sql query")
+	}
+	//queries only read data, it is safe to retry them
+	req, err := d.newJSONRequest(WithIdempotent(ctx, true), http.MethodPost, "/v1/datasets/query/execute/"+datasetID, queryRequest{SQL: sql})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.doStream(req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	src := &queryRowSource{body: resp.Body, dec: json.NewDecoder(resp.Body)}
+	src.dec.UseNumber()
+	if err := src.start(); err != nil {
+		drainAndClose(resp.Body)
+		return nil, fmt.Errorf("error: cannot parse query response of dataset %s - %v", datasetID, err)
+	}
+	return &Rows{columns: src.columns, src: src}, nil
+}
+
+//queryRowSource reads the json query response token by token so rows are decoded as they arrive.
+//The response is an object with "columns" and "metadata" ahead of the "rows" array;
+//when the rows come first they are buffered until the columns are known.
+type queryRowSource struct {
+	body io.Closer
+	dec  *json.Decoder
+
+	datasetID  string
+	names      []string
+	metadata   []ColumnMetadata
+	columns    []Column
+	numRows    int
+	numColumns int
+	fromCache  bool
+
+	//buffered rows, used when "rows" precedes "columns"
+	buffered [][]interface{}
+	inRows   bool
+	line     int
+}
+
+//start reads the response up to the first row
+func (s *queryRowSource) start() error {
+	if err := expectDelim(s.dec, '{'); err != nil {
+		return err
+	}
+	for s.dec.More() {
+		key, err := s.dec.Token()
+		if err != nil {
+			return err
+		}
+		if key == "rows" && s.names != nil {
+			s.setColumns()
+			if err := expectDelim(s.dec, '['); err != nil {
+				return err
+			}
+			s.inRows = true
+			return nil
+		}
+		if err := s.field(key); err != nil {
+			return err
+		}
+	}
+	s.setColumns()
+	for i, row := range s.buffered {
+		values, err := s.decode(row)
+		if err != nil {
+			return err
+		}
+		s.buffered[i] = values
+	}
+	return nil
+}
+
+//field decodes the value of a top level key other than streamed rows
+func (s *queryRowSource) field(key json.Token) error {
+	switch key {
+	case "datasource":
+		return s.dec.Decode(&s.datasetID)
+	case "columns":
+		return s.dec.Decode(&s.names)
+	case "metadata":
+		return s.dec.Decode(&s.metadata)
+	case "rows":
+		return s.dec.Decode(&s.buffered)
+	case "numRows":
+		return s.dec.Decode(&s.numRows)
+	case "numColumns":
+		return s.dec.Decode(&s.numColumns)
+	case "fromcache":
+		return s.dec.Decode(&s.fromCache)
+	}
+	var skip json.RawMessage
+	return s.dec.Decode(&skip)
+}
+
+func (s *queryRowSource) setColumns() {
+	s.columns = make([]Column, len(s.names))
+	for i, name := range s.names {
+		s.columns[i] = Column{Name: name, Type: ColumnTypeString}
+		if i < len(s.metadata) && s.metadata[i].Type != "" {
+			s.columns[i].Type = s.metadata[i].Type
+		}
+	}
+}
+
+func (s *queryRowSource) next() ([]interface{}, error) {
+	if !s.inRows {
+		if len(s.buffered) == 0 {
+			return nil, io.EOF
+		}
+		values := s.buffered[0]
+		s.buffered = s.buffered[1:]
+		return values, nil
+	}
+	if !s.dec.More() {
+		//read the end of the rows and the trailing fields such as numRows
+		s.inRows = false
+		if _, err := s.dec.Token(); err != nil {
+			return nil, err
+		}
+		for s.dec.More() {
+			key, err := s.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if err := s.field(key); err != nil {
+				return nil, err
+			}
+		}
+		return nil, io.EOF
+	}
+	var row []interface{}
+	if err := s.dec.Decode(&row); err != nil {
+		return nil, err
+	}
+	return s.decode(row)
+}
+
+//decode converts the json values of a row by column type, see Rows for the resulting Go types
+func (s *queryRowSource) decode(row []interface{}) ([]interface{}, error) {
+	s.line++
+	if len(row) != len(s.columns) {
+		return nil, fmt.Errorf("error: row %d has %d values, want %d", s.line, len(row), len(s.columns))
+	}
+	values := make([]interface{}, len(row))
+	for i, v := range row {
+		var err error
+		switch v := v.(type) {
+		case nil:
+		case string:
+			values[i], err = ParseValue(s.columns[i].Type, v)
+		case json.Number:
+			values[i], err = ParseValue(s.columns[i].Type, v.String())
+		default:
+			values[i] = v
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error: row %d column %s - %v", s.line, s.columns[i].Name, err)
+		}
+	}
+	return values, nil
+}
+
+func (s *queryRowSource) close() error {
+	return s.body.Close()
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("unexpected %v, want %v", t, delim)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package domoapi
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+const (
+	queryJSON = `{
+		"datasource": "4405ff58-1957-45f0-82bd-914d989a3ea3",
+		"columns": ["Friend", "Attending", "Budget", "Day", "Since"],
+		"metadata": [
+			{"type": "STRING", "dataSourceId": "4405ff58-1957-45f0-82bd-914d989a3ea3", "maxLength": -1, "minLength": -1, "periodIndex": 0},
+			{"type": "LONG", "dataSourceId": "4405ff58-1957-45f0-82bd-914d989a3ea3", "maxLength": -1, "minLength": -1, "periodIndex": 0},
+			{"type": "DECIMAL", "dataSourceId": "4405ff58-1957-45f0-82bd-914d989a3ea3", "maxLength": -1, "minLength": -1, "periodIndex": 0},
+			{"type": "DATE", "dataSourceId": "4405ff58-1957-45f0-82bd-914d989a3ea3", "maxLength": -1, "minLength": -1, "periodIndex": 0},
+			{"type": "DATETIME", "dataSourceId": "4405ff58-1957-45f0-82bd-914d989a3ea3", "maxLength": -1, "minLength": -1, "periodIndex": 0}
+		],
+		"rows": [
+			["Euler", 3, 12.50, "2016-06-21", "2016-06-21T17:20:36"],
+			["Gauss", null, 0.1, "2016-06-22", "2016-06-22T08:00:00"]
+		],
+		"numRows": 2,
+		"numColumns": 5,
+		"fromcache": true
+	}`
+	//same result with the rows ahead of the columns
+	queryRowsFirstJSON = `{
+		"rows": [["Euler", 3]],
+		"columns": ["Friend", "Attending"],
+		"metadata": [{"type": "STRING"}, {"type": "LONG"}],
+		"numRows": 1,
+		"numColumns": 2
+	}`
+)
+
+func queryAPI(t *testing.T, ctrl *gomock.Controller, response string, statusCode int) *DomoAPI {
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPost || req.URL.Path != "/v1/datasets/query/execute/4405ff58-1957-45f0-82bd-914d989a3ea3" {
+			t.Errorf("request = %v %v", req.Method, req.URL)
+		}
+		if !IsIdempotent(req) {
+			t.Errorf("query request should be idempotent")
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		var sent queryRequest
+		if err := json.Unmarshal(body, &sent); err != nil || sent.SQL != "SELECT * FROM table" {
+			t.Errorf("request body = %s", body)
+		}
+		return getMockResponse(response, statusCode), nil
+	})
+	return &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+}
+
+func TestDomoAPI_QueryDataset(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		code     int
+		want     *QueryResult
+		wantErr  bool
+	}{
+		{
+			name:     "typed rows",
+			response: queryJSON,
+			code:     200,
+			want: &QueryResult{
+				DatasetID: "4405ff58-1957-45f0-82bd-914d989a3ea3",
+				Columns: []Column{
+					{Name: "Friend", Type: ColumnTypeString},
+					{Name: "Attending", Type: ColumnTypeLong},
+					{Name: "Budget", Type: ColumnTypeDecimal},
+					{Name: "Day", Type: ColumnTypeDate},
+					{Name: "Since", Type: ColumnTypeDateTime},
+				},
+				Rows: [][]interface{}{
+					{"Euler", int64(3), big.NewRat(25, 2), time.Date(2016, 6, 21, 0, 0, 0, 0, time.UTC), time.Date(2016, 6, 21, 17, 20, 36, 0, time.UTC)},
+					{"Gauss", nil, big.NewRat(1, 10), time.Date(2016, 6, 22, 0, 0, 0, 0, time.UTC), time.Date(2016, 6, 22, 8, 0, 0, 0, time.UTC)},
+				},
+				NumRows:    2,
+				NumColumns: 5,
+				FromCache:  true,
+			},
+		},
+		{
+			name:     "rows ahead of columns",
+			response: queryRowsFirstJSON,
+			code:     200,
+			want: &QueryResult{
+				Columns:    []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Attending", Type: ColumnTypeLong}},
+				Rows:       [][]interface{}{{"Euler", int64(3)}},
+				NumRows:    1,
+				NumColumns: 2,
+			},
+		},
+		{
+			name:     "invalid value",
+			response: `{"columns": ["Attending"], "metadata": [{"type": "LONG"}], "rows": [["many"]]}`,
+			code:     200,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid response",
+			response: `[]`,
+			code:     200,
+			wantErr:  true,
+		},
+		{
+			name:     "bad query",
+			response: errorJSON,
+			code:     400,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			domoAPI := queryAPI(t, ctrl, tt.response, tt.code)
+
+			got, err := domoAPI.QueryDataset(context.Background(), "4405ff58-1957-45f0-82bd-914d989a3ea3", "SELECT * FROM table")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DomoAPI.QueryDataset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got.Metadata = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DomoAPI.QueryDataset() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomoAPI_QueryRows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	domoAPI := queryAPI(t, ctrl, queryJSON, 200)
+
+	rows, err := domoAPI.QueryRows(context.Background(), "4405ff58-1957-45f0-82bd-914d989a3ea3", "SELECT * FROM table")
+	if err != nil {
+		t.Fatalf("DomoAPI.QueryRows() error = %v", err)
+	}
+	defer rows.Close()
+
+	var friends []string
+	for rows.Next() {
+		var p struct {
+			Friend    string
+			Attending *int64
+			Day       Date
+		}
+		if err := rows.Scan(&p); err != nil {
+			t.Fatalf("Rows.Scan() error = %v", err)
+		}
+		friends = append(friends, p.Friend+" "+p.Day.String())
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Rows.Err() = %v", err)
+	}
+	if got := strings.Join(friends, ","); got != "Euler 2016-06-21,Gauss 2016-06-22" {
+		t.Errorf("scanned rows = %v", got)
+	}
+}
+
+func TestDomoAPI_QueryRows_missingArguments(t *testing.T) {
+	domoAPI := &DomoAPI{tokens: &tokenSource{token: &sampleToken}}
+	if _, err := domoAPI.QueryRows(context.Background(), "", "SELECT * FROM table"); err == nil {
+		t.Errorf("DomoAPI.QueryRows() without dataset id should fail")
+	}
+	if _, err := domoAPI.QueryRows(context.Background(), "4405ff58-1957-45f0-82bd-914d989a3ea3", ""); err == nil {
+		t.Errorf("DomoAPI.QueryRows() without sql should fail")
+	}
+}
@@ -0,0 +1,79 @@
+package domoapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+//newRequest builds a request for the given api path, e.g. "/v1/datasets"
+func (d *DomoAPI) newRequest(ctx context.Context, method string, path string, body io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, d.config.endpoint(path), body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+//newJSONRequest builds a request with v encoded as json body
+func (d *DomoAPI) newJSONRequest(ctx context.Context, method string, path string, v interface{}) (*http.Request, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return d.newRequest(ctx, method, path, bytes.NewReader(body), "application/json")
+}
+
+//checkResponse checks the outcome of sending req. Transport errors are returned as is and any status
+//other than the wanted ones becomes an APIError, in both cases the body is already closed.
+//On success the caller owns resp.Body and must close it.
+func checkResponse(req *http.Request, resp *http.Response, err error, want ...int) (*http.Response, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, statusCode := range want {
+		if resp.StatusCode == statusCode {
+			return resp, nil
+		}
+	}
+	defer drainAndClose(resp.Body)
+	return nil, readAPIError(req, resp)
+}
+
+//doStream sends an authorized request and leaves the body of a successful response open for the caller
+func (d *DomoAPI) doStream(req *http.Request, want ...int) (*http.Response, error) {
+	resp, err := d.do(req)
+	return checkResponse(req, resp, err, want...)
+}
+
+//doRead sends an authorized request and returns the whole response body
+func (d *DomoAPI) doRead(req *http.Request, want ...int) ([]byte, error) {
+	resp, err := d.doStream(req, want...)
+	if err != nil {
+		return nil, err
+	}
+	defer drainAndClose(resp.Body)
+	return ioutil.ReadAll(resp.Body)
+}
+
+//doJSON sends an authorized request and decodes the json response into out, unless out is nil
+func (d *DomoAPI) doJSON(req *http.Request, out interface{}, want ...int) error {
+	body, err := d.doRead(req, want...)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("error: cannot parse json response of %s %s - %v", req.Method, req.URL, err)
+	}
+	return nil
+}
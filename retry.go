@@ -0,0 +1,218 @@
+package domoapi
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	//DefaultMaxAttempts is the number of attempts, including the first one, used when ExponentialBackoff.MaxAttempts is not set
+	DefaultMaxAttempts = 4
+	//DefaultBaseDelay is the delay before the first retry used when ExponentialBackoff.BaseDelay is not set
+	DefaultBaseDelay = 500 * time.Millisecond
+	//DefaultMaxDelay is the upper bound of a single delay used when ExponentialBackoff.MaxDelay is not set
+	DefaultMaxDelay = 30 * time.Second
+)
+
+//RetryPolicy decides whether a request is sent again and how long to wait before doing so
+type RetryPolicy interface {
+	//Retry is called after every attempt, counted from 1, with the response or transport error of that attempt.
+	//It returns the delay before the next attempt, or false when the request must not be retried.
+	Retry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool)
+}
+
+//ExponentialBackoff retries transport errors and 429, 500, 502, 503 and 504 responses of idempotent requests.
+//The delay doubles on every attempt with full jitter, unless domo sends a Retry-After header.
+type ExponentialBackoff struct {
+	//MaxAttempts is the total number of attempts including the first one. Defaults to DefaultMaxAttempts
+	MaxAttempts int
+	//BaseDelay is the delay before the first retry. Defaults to DefaultBaseDelay
+	BaseDelay time.Duration
+	//MaxDelay caps a single delay, including the one requested by Retry-After. Defaults to DefaultMaxDelay
+	MaxDelay time.Duration
+}
+
+//Retry implements RetryPolicy
+func (b *ExponentialBackoff) Retry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	maxAttempts := b.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if attempt >= maxAttempts || !IsIdempotent(req) {
+		return 0, false
+	}
+	if err != nil {
+		if req.Context().Err() != nil {
+			return 0, false
+		}
+		return b.backoff(attempt), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+	if delay, ok := retryAfter(resp); ok {
+		return b.limit(delay), true
+	}
+	return b.backoff(attempt), true
+}
+
+func (b *ExponentialBackoff) backoff(attempt int) time.Duration {
+	base := b.BaseDelay
+	if base == 0 {
+		base = DefaultBaseDelay
+	}
+	delay := b.maxDelay()
+	if shift := uint(attempt - 1); shift < 20 && base<<shift < delay {
+		delay = base << shift
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+func (b *ExponentialBackoff) limit(delay time.Duration) time.Duration {
+	if maxDelay := b.maxDelay(); delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
+func (b *ExponentialBackoff) maxDelay() time.Duration {
+	if b.MaxDelay == 0 {
+		return DefaultMaxDelay
+	}
+	return b.MaxDelay
+}
+
+//retryAfter parses the Retry-After header, given either in seconds or as http date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		delay := time.Until(at)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+type contextKey int
+
+const idempotentKey contextKey = iota
+
+//WithIdempotent marks the requests made with ctx as safe (or not) to send more than once,
+//overriding the default derived from the http method
+func WithIdempotent(ctx context.Context, idempotent bool) context.Context {
+	return context.WithValue(ctx, idempotentKey, idempotent)
+}
+
+//IsIdempotent reports whether req may be sent more than once.
+//GET, HEAD, OPTIONS, PUT and DELETE requests are idempotent unless marked otherwise with WithIdempotent.
+func IsIdempotent(req *http.Request) bool {
+	if idempotent, ok := req.Context().Value(idempotentKey).(bool); ok {
+		return idempotent
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+//RetryHandler is a RequestHandlerService which sends failed requests again as decided by Policy.
+//Request bodies are replayed with http.Request.GetBody, requests without it are never retried.
+type RetryHandler struct {
+	Next RequestHandlerService
+	//Policy decides the retries, an ExponentialBackoff with default settings when nil
+	Policy RetryPolicy
+}
+
+//NewRetryHandler wraps next with the given retry policy, a nil policy uses an ExponentialBackoff with default settings
+func NewRetryHandler(next RequestHandlerService, policy RetryPolicy) *RetryHandler {
+	return &RetryHandler{
+		Next:   next,
+		Policy: policy,
+	}
+}
+
+//Handler handles http client request.
+func (h *RetryHandler) Handler(req *http.Request) (*http.Response, error) {
+	return h.HandlerContext(req.Context(), req)
+}
+
+//HandlerContext handles http client request within ctx, waiting between attempts until ctx is done.
+func (h *RetryHandler) HandlerContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	policy := h.Policy
+	if policy == nil {
+		policy = &ExponentialBackoff{}
+	}
+	req = req.WithContext(ctx)
+	for attempt := 1; ; attempt++ {
+		resp, err := sendWith(h.Next, req)
+		delay, retry := policy.Retry(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		next, ok := rewind(req)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			drainAndClose(resp.Body)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		req = next
+	}
+}
+
+//sendWith passes req to handler, using HandlerContext when it is available
+func sendWith(handler RequestHandlerService, req *http.Request) (*http.Response, error) {
+	if h, ok := handler.(ContextRequestHandlerService); ok {
+		return h.HandlerContext(req.Context(), req)
+	}
+	return handler.Handler(req)
+}
+
+//rewind returns a copy of req with a fresh body, or false when the body cannot be replayed
+func rewind(req *http.Request) (*http.Request, bool) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	next.Body = body
+	return next, true
+}
+
+//drainAndClose reads what is left of body so the connection can be reused, then closes it
+func drainAndClose(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, body)
+	body.Close()
+}
@@ -0,0 +1,247 @@
+package domoapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+func getRetryAfterResponse(statusCode int, retryAfter string) *http.Response {
+	resp := getMockResponse(errorJSON, statusCode)
+	resp.Header = http.Header{}
+	resp.Header.Set("Retry-After", retryAfter)
+	return resp
+}
+
+func TestExponentialBackoff_Retry(t *testing.T) {
+	policy := &ExponentialBackoff{
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		MaxDelay:    10 * time.Second,
+	}
+	get, _ := http.NewRequest(http.MethodGet, "https://api.domo.example.com/v1/datasets", nil)
+	post, _ := http.NewRequest(http.MethodPost, "https://api.domo.example.com/v1/datasets", nil)
+	appendReq, _ := http.NewRequestWithContext(WithIdempotent(context.Background(), false), http.MethodPut, "https://api.domo.example.com/v1/datasets/id/data", nil)
+
+	tests := []struct {
+		name      string
+		req       *http.Request
+		resp      *http.Response
+		err       error
+		attempt   int
+		wantRetry bool
+		wantDelay time.Duration
+		maxDelay  time.Duration
+	}{
+		{
+			name:      "retry 503 with backoff",
+			req:       get,
+			resp:      getMockResponse(errorJSON, 503),
+			attempt:   2,
+			wantRetry: true,
+			maxDelay:  2 * time.Second,
+		},
+		{
+			name:      "retry transport error",
+			req:       get,
+			err:       fmt.Errorf("connection reset"),
+			attempt:   1,
+			wantRetry: true,
+			maxDelay:  time.Second,
+		},
+		{
+			name:      "respect Retry-After seconds",
+			req:       get,
+			resp:      getRetryAfterResponse(429, "7"),
+			attempt:   1,
+			wantRetry: true,
+			wantDelay: 7 * time.Second,
+		},
+		{
+			name:      "cap Retry-After to MaxDelay",
+			req:       get,
+			resp:      getRetryAfterResponse(429, "120"),
+			attempt:   1,
+			wantRetry: true,
+			wantDelay: 10 * time.Second,
+		},
+		{
+			name:    "attempt budget exhausted",
+			req:     get,
+			resp:    getMockResponse(errorJSON, 503),
+			attempt: 3,
+		},
+		{
+			name:    "do not retry client errors",
+			req:     get,
+			resp:    getMockResponse(errorJSON, 400),
+			attempt: 1,
+		},
+		{
+			name:    "do not retry post",
+			req:     post,
+			resp:    getMockResponse(errorJSON, 503),
+			attempt: 1,
+		},
+		{
+			name:    "do not retry requests marked as not idempotent",
+			req:     appendReq,
+			resp:    getMockResponse(errorJSON, 503),
+			attempt: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := policy.Retry(tt.req, tt.resp, tt.err, tt.attempt)
+			if retry != tt.wantRetry {
+				t.Errorf("ExponentialBackoff.Retry() retry = %v, want %v", retry, tt.wantRetry)
+				return
+			}
+			if tt.maxDelay > 0 && (delay < 0 || delay > tt.maxDelay) {
+				t.Errorf("ExponentialBackoff.Retry() delay = %v, want between 0 and %v", delay, tt.maxDelay)
+			}
+			if tt.maxDelay == 0 && delay != tt.wantDelay {
+				t.Errorf("ExponentialBackoff.Retry() delay = %v, want %v", delay, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestRetryHandler_Handler(t *testing.T) {
+	policy := &ExponentialBackoff{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantErr    bool
+		handler    func(ctrl *gomock.Controller) RequestHandlerService
+	}{
+		{
+			name:       "retry until success",
+			method:     http.MethodGet,
+			wantStatus: 200,
+			handler: func(ctrl *gomock.Controller) RequestHandlerService {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				gomock.InOrder(
+					rmock.EXPECT().Handler(gomock.Any()).Return(getRetryAfterResponse(429, "0"), nil),
+					rmock.EXPECT().Handler(gomock.Any()).Return(nil, fmt.Errorf("connection reset")),
+					rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse("[]", 200), nil),
+				)
+				return rmock
+			},
+		},
+		{
+			name:       "return last response when attempts run out",
+			method:     http.MethodGet,
+			wantStatus: 503,
+			handler: func(ctrl *gomock.Controller) RequestHandlerService {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 503), nil).Times(3)
+				return rmock
+			},
+		},
+		{
+			name:       "replay request body",
+			method:     http.MethodPut,
+			body:       "1,2,3\n",
+			wantStatus: 204,
+			handler: func(ctrl *gomock.Controller) RequestHandlerService {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				checkBody := func(req *http.Request) {
+					body, _ := ioutil.ReadAll(req.Body)
+					if string(body) != "1,2,3\n" {
+						t.Errorf("request body = %q, want %q", body, "1,2,3\n")
+					}
+				}
+				gomock.InOrder(
+					rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+						checkBody(req)
+						return getMockResponse(errorJSON, 502), nil
+					}),
+					rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+						checkBody(req)
+						return getMockResponse("", 204), nil
+					}),
+				)
+				return rmock
+			},
+		},
+		{
+			name:       "do not retry post",
+			method:     http.MethodPost,
+			wantStatus: 503,
+			handler: func(ctrl *gomock.Controller) RequestHandlerService {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 503), nil)
+				return rmock
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := NewRetryHandler(tt.handler(ctrl), policy)
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, _ := http.NewRequest(tt.method, "https://api.domo.example.com/v1/datasets", body)
+			resp, err := h.Handler(req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RetryHandler.Handler() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("RetryHandler.Handler() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRetryHandler_HandlerContext_Canceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		cancel()
+		return getRetryAfterResponse(429, "60"), nil
+	})
+	h := NewRetryHandler(rmock, &ExponentialBackoff{MaxDelay: time.Minute})
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.domo.example.com/v1/datasets", nil)
+	if _, err := h.HandlerContext(ctx, req); err != context.Canceled {
+		t.Errorf("RetryHandler.HandlerContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRetryHandler_NilPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	gomock.InOrder(
+		rmock.EXPECT().Handler(gomock.Any()).Return(getRetryAfterResponse(429, "0"), nil),
+		rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse("[]", 200), nil),
+	)
+	h := NewRetryHandler(rmock, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.domo.example.com/v1/datasets", nil)
+	resp, err := h.Handler(req)
+	if err != nil || resp.StatusCode != 200 {
+		t.Errorf("RetryHandler.Handler() = %v, %v, want status 200", resp, err)
+	}
+}
@@ -0,0 +1,244 @@
+package domoapi
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+//Layouts of DATE and DATETIME values. DATETIME values without zone are read as UTC.
+const dateLayout = "2006-01-02"
+
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+//Rows iterates over the typed rows of a dataset. Column values are decoded by column type:
+//STRING to string, LONG to int64, DOUBLE to float64, DECIMAL to *big.Rat and DATE and DATETIME to time.Time.
+//Empty values of other columns than STRING are nil.
+//
+//	rows, err := d.ReadRows(ctx, datasetID)
+//	...
+//	defer rows.Close()
+//	for rows.Next() {
+//		var p Party
+//		if err := rows.Scan(&p); err != nil { ... }
+//	}
+//	err = rows.Err()
+type Rows struct {
+	columns []Column
+	src     rowSource
+	values  []interface{}
+	err     error
+	closed  bool
+}
+
+//rowSource produces the decoded values of one row per call and io.EOF after the last one
+type rowSource interface {
+	next() ([]interface{}, error)
+	close() error
+}
+
+//ReadRows fetches the dataset schema and streams the dataset export as typed rows.
+//The caller must close the returned Rows.
+func (d *DomoAPI) ReadRows(ctx context.Context, datasetID string) (*Rows, error) {
+	ds, err := d.GetDatasetContext(ctx, datasetID)
+	if err != nil {
+		return nil, err
+	}
+	if ds.Schema == nil {
+		return nil, fmt.Errorf("error: dataset %s has no schema", datasetID)
+	}
+	body, err := d.ExportDataset(ctx, datasetID, ExportOptions{IncludeHeader: true})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := newCSVRows(body, ds.Schema)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	return rows, nil
+}
+
+//newCSVRows reads csv data with a header line from body, decoding values with the column types of schema
+func newCSVRows(body io.ReadCloser, schema *Schema) (*Rows, error) {
+	r := csv.NewReader(body)
+	r.ReuseRecord = true
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("error: export has no header line")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	//the export's header decides the column order, columns missing in the schema are read as STRING
+	columns := make([]Column, len(header))
+	for i, name := range header {
+		columns[i] = Column{Name: name, Type: ColumnTypeString}
+		for _, c := range schema.Columns {
+			if c.Name == name {
+				columns[i] = c
+				break
+			}
+		}
+	}
+	return &Rows{
+		columns: columns,
+		src: &csvRowSource{
+			r:       r,
+			body:    body,
+			columns: columns,
+		},
+	}, nil
+}
+
+//Columns returns the columns of the rows in order
+func (r *Rows) Columns() []Column {
+	return r.columns
+}
+
+//Next advances to the next row, it returns false at the end of the data or on error
+func (r *Rows) Next() bool {
+	if r.closed || r.err != nil {
+		return false
+	}
+	values, err := r.src.next()
+	if err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+		r.Close()
+		return false
+	}
+	r.values = values
+	return true
+}
+
+//Err returns the error which stopped the iteration, if any
+func (r *Rows) Err() error {
+	return r.err
+}
+
+//Close releases the underlying export. It is safe to call Close more than once.
+func (r *Rows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.src.close()
+}
+
+//Values returns the decoded values of the current row in column order
+func (r *Rows) Values() []interface{} {
+	return r.values
+}
+
+//Map returns the current row keyed by column name
+func (r *Rows) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(r.columns))
+	for i, c := range r.columns {
+		m[c.Name] = r.values[i]
+	}
+	return m
+}
+
+//Scan copies the current row into dest, which must be a pointer to a struct or to a map[string]interface{}.
+//Struct fields are matched to columns by their `domo:"name"` tag or field name, columns without field are skipped.
+func (r *Rows) Scan(dest interface{}) error {
+	if m, ok := dest.(*map[string]interface{}); ok {
+		*m = r.Map()
+		return nil
+	}
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("error: Scan needs a non-nil pointer to a struct, got %T", dest)
+	}
+	v = v.Elem()
+	fields := typeFields(v.Type())
+	for i, c := range r.columns {
+		f, ok := fieldByName(fields, c.Name)
+		if !ok {
+			continue
+		}
+		if err := assignValue(fieldByIndex(v, f.index), r.values[i]); err != nil {
+			return fmt.Errorf("error: column %s - %v", c.Name, err)
+		}
+	}
+	return nil
+}
+
+type csvRowSource struct {
+	r       *csv.Reader
+	body    io.Closer
+	columns []Column
+	line    int
+}
+
+func (s *csvRowSource) next() ([]interface{}, error) {
+	record, err := s.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	s.line++
+	if len(record) != len(s.columns) {
+		return nil, fmt.Errorf("error: row %d has %d values, want %d", s.line, len(record), len(s.columns))
+	}
+	values := make([]interface{}, len(record))
+	for i, field := range record {
+		v, err := ParseValue(s.columns[i].Type, field)
+		if err != nil {
+			return nil, fmt.Errorf("error: row %d column %s - %v", s.line, s.columns[i].Name, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (s *csvRowSource) close() error {
+	return s.body.Close()
+}
+
+//ParseValue decodes a csv value of the given domo column type, see Rows for the resulting Go types
+func ParseValue(columnType string, s string) (interface{}, error) {
+	if s == "" {
+		if columnType == ColumnTypeString {
+			return "", nil
+		}
+		return nil, nil
+	}
+	switch columnType {
+	case ColumnTypeLong:
+		return strconv.ParseInt(s, 10, 64)
+	case ColumnTypeDouble:
+		return strconv.ParseFloat(s, 64)
+	case ColumnTypeDecimal:
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return nil, fmt.Errorf("invalid DECIMAL %q", s)
+		}
+		return r, nil
+	case ColumnTypeDate:
+		return time.Parse(dateLayout, s)
+	case ColumnTypeDateTime:
+		return parseDateTime(s)
+	}
+	return s, nil
+}
+
+func parseDateTime(s string) (time.Time, error) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid DATETIME %q", s)
+}
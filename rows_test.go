@@ -0,0 +1,197 @@
+package domoapi
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+var (
+	typedDatasetJSON = `{
+		"id": "4405ff58-1957-45f0-82bd-914d989a3ea3",
+		"name": "Leonhard Euler Party",
+		"schema": {
+		  "columns": [
+			{"type": "STRING", "name": "Friend"},
+			{"type": "LONG", "name": "Guests"},
+			{"type": "DOUBLE", "name": "Score"},
+			{"type": "DECIMAL", "name": "Budget"},
+			{"type": "DATE", "name": "Day"},
+			{"type": "DATETIME", "name": "Arrival"}
+		  ]
+		}
+	}`
+	typedCSV = "Friend,Guests,Score,Budget,Day,Arrival\n" +
+		"Gauss,3,9.5,1234.56,1777-04-30,1777-04-30T10:15:00\n" +
+		"\"Bernoulli, Daniel\",,,,,\n"
+)
+
+type partyRow struct {
+	Friend  string     `domo:"Friend"`
+	Guests  *int       `domo:"Guests"`
+	Score   float64    `domo:"Score"`
+	Budget  *big.Rat   `domo:"Budget"`
+	Day     time.Time  `domo:"Day"`
+	Arrival *time.Time `domo:"Arrival"`
+	Ignored string     `domo:"-"`
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		columnType string
+		value      string
+		want       interface{}
+		wantErr    bool
+	}{
+		{name: "string", columnType: ColumnTypeString, value: "Gauss", want: "Gauss"},
+		{name: "empty string", columnType: ColumnTypeString, value: "", want: ""},
+		{name: "long", columnType: ColumnTypeLong, value: "42", want: int64(42)},
+		{name: "empty long", columnType: ColumnTypeLong, value: "", want: nil},
+		{name: "invalid long", columnType: ColumnTypeLong, value: "4.2", wantErr: true},
+		{name: "double", columnType: ColumnTypeDouble, value: "4.25", want: 4.25},
+		{name: "decimal", columnType: ColumnTypeDecimal, value: "0.10", want: big.NewRat(1, 10)},
+		{name: "date", columnType: ColumnTypeDate, value: "2016-06-21", want: time.Date(2016, 6, 21, 0, 0, 0, 0, time.UTC)},
+		{name: "datetime with zone", columnType: ColumnTypeDateTime, value: "2016-06-21T17:20:36Z", want: time.Date(2016, 6, 21, 17, 20, 36, 0, time.UTC)},
+		{name: "datetime without zone", columnType: ColumnTypeDateTime, value: "2016-06-21 17:20:36", want: time.Date(2016, 6, 21, 17, 20, 36, 0, time.UTC)},
+		{name: "invalid datetime", columnType: ColumnTypeDateTime, value: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseValue(tt.columnType, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if r, ok := tt.want.(*big.Rat); ok {
+				if got.(*big.Rat).Cmp(r) != 0 {
+					t.Errorf("ParseValue() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomoAPI_ReadRows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	gomock.InOrder(
+		rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/v1/datasets/dummy_id" {
+				t.Errorf("request url = %v", req.URL)
+			}
+			return getMockResponse(typedDatasetJSON, 200), nil
+		}),
+		rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(typedCSV, 200), nil),
+	)
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	rows, err := domoAPI.ReadRows(context.Background(), "dummy_id")
+	if err != nil {
+		t.Fatalf("DomoAPI.ReadRows() error = %v", err)
+	}
+	defer rows.Close()
+
+	var got []partyRow
+	var maps []map[string]interface{}
+	for rows.Next() {
+		var p partyRow
+		if err := rows.Scan(&p); err != nil {
+			t.Fatalf("Rows.Scan() error = %v", err)
+		}
+		got = append(got, p)
+		maps = append(maps, rows.Map())
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Rows.Err() = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("read %d rows, want 2", len(got))
+	}
+
+	first := got[0]
+	arrival := time.Date(1777, 4, 30, 10, 15, 0, 0, time.UTC)
+	if first.Friend != "Gauss" || first.Guests == nil || *first.Guests != 3 || first.Score != 9.5 ||
+		first.Budget.Cmp(big.NewRat(123456, 100)) != 0 || !first.Day.Equal(time.Date(1777, 4, 30, 0, 0, 0, 0, time.UTC)) ||
+		first.Arrival == nil || !first.Arrival.Equal(arrival) {
+		t.Errorf("first row = %+v", first)
+	}
+	second := got[1]
+	if second.Friend != "Bernoulli, Daniel" || second.Guests != nil || second.Budget != nil || second.Arrival != nil || !second.Day.IsZero() {
+		t.Errorf("second row = %+v", second)
+	}
+	if maps[0]["Guests"] != int64(3) || maps[1]["Guests"] != nil {
+		t.Errorf("Rows.Map() = %v", maps)
+	}
+}
+
+func TestRows_ScanErrors(t *testing.T) {
+	rows := &Rows{
+		columns: []Column{{Name: "Guests", Type: ColumnTypeLong}},
+		values:  []interface{}{int64(300)},
+	}
+	var small struct {
+		Guests int8
+	}
+	if err := rows.Scan(&small); err == nil {
+		t.Errorf("Rows.Scan() into overflowing field error = nil")
+	}
+	var notStruct int
+	if err := rows.Scan(&notStruct); err == nil {
+		t.Errorf("Rows.Scan() into int error = nil")
+	}
+}
+
+type scanInner struct {
+	A string
+}
+
+func TestRows_ScanEmbeddedPointer(t *testing.T) {
+	type Exported struct {
+		A string
+	}
+	rows := &Rows{
+		columns: []Column{{Name: "A", Type: ColumnTypeString}, {Name: "B", Type: ColumnTypeString}},
+		values:  []interface{}{"Euler", "Gauss"},
+	}
+
+	var hidden struct {
+		*scanInner
+		B string
+	}
+	if err := rows.Scan(&hidden); err != nil {
+		t.Fatalf("Rows.Scan() with an unexported embedded pointer error = %v", err)
+	}
+	if hidden.scanInner != nil || hidden.B != "Gauss" {
+		t.Errorf("Rows.Scan() = %+v", hidden)
+	}
+
+	var allocated struct {
+		*Exported
+		B string
+	}
+	if err := rows.Scan(&allocated); err != nil {
+		t.Fatalf("Rows.Scan() with an embedded pointer error = %v", err)
+	}
+	if allocated.Exported == nil || allocated.A != "Euler" || allocated.B != "Gauss" {
+		t.Errorf("Rows.Scan() = %+v", allocated)
+	}
+}
@@ -0,0 +1,130 @@
+package domoapi
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+//Date is a calendar date without time of day or zone, a Go type for DATE columns
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+//DateOf returns the date of t in t's location
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+//ParseDate parses a date in the yyyy-mm-dd format
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+//String returns the date in the yyyy-mm-dd format
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+//IsZero reports whether d is the zero Date
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+//In returns the time at midnight of d in loc
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+//MarshalText implements encoding.TextMarshaler
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+//UnmarshalText implements encoding.TextUnmarshaler
+func (d *Date) UnmarshalText(data []byte) error {
+	var err error
+	*d, err = ParseDate(string(data))
+	return err
+}
+
+//SchemaFromStruct derives a Schema from the fields of a struct, given as value or pointer.
+//Columns are named and typed with the `domo:"name,type"` tag, without type the domo type follows the Go type:
+//
+//	string                        STRING
+//	int*, uint*                   LONG
+//	float*                        DOUBLE
+//	big.Rat, big.Float            DECIMAL
+//	time.Time                     DATETIME
+//	Date                          DATE
+//
+//Pointers map like the type they point to. Fields tagged `domo:"-"` and unexported fields are skipped.
+//The same struct can be used with CreateDataset, Encoder and Rows.Scan.
+func SchemaFromStruct(v interface{}) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("error: SchemaFromStruct needs a struct, got %T", v)
+	}
+
+	schema := &Schema{}
+	seen := make(map[string]bool)
+	for _, f := range typeFields(t) {
+		if seen[f.name] {
+			return nil, fmt.Errorf("error: %s has more than one field for column %s", t, f.name)
+		}
+		seen[f.name] = true
+
+		columnType := f.columnType
+		if columnType == "" {
+			var ok bool
+			if columnType, ok = columnTypeOf(f.typ); !ok {
+				return nil, fmt.Errorf("error: field %s of %s has type %s without domo column type, tag it with `domo:\"name,type\"`", f.name, t, f.typ)
+			}
+		} else if !validColumnType(columnType) {
+			return nil, fmt.Errorf("error: field %s of %s has unknown column type %q", f.name, t, columnType)
+		}
+		schema.Columns = append(schema.Columns, Column{
+			Type: columnType,
+			Name: f.name,
+		})
+	}
+	if len(schema.Columns) == 0 {
+		return nil, fmt.Errorf("error: %s has no exported fields", t)
+	}
+	return schema, nil
+}
+
+//columnTypeOf returns the domo column type for Go type t
+func columnTypeOf(t reflect.Type) (string, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return ColumnTypeDateTime, true
+	case dateType:
+		return ColumnTypeDate, true
+	case bigRatType, bigFloatType:
+		return ColumnTypeDecimal, true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return ColumnTypeString, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ColumnTypeLong, true
+	case reflect.Float32, reflect.Float64:
+		return ColumnTypeDouble, true
+	}
+	return "", false
+}
@@ -0,0 +1,184 @@
+package domoapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type taggedParty struct {
+	Friend    string     `domo:"Friend"`
+	Guests    *int64     `domo:"Guests"`
+	Score     float32    `domo:"Score"`
+	Budget    big.Rat    `domo:"Budget"`
+	Tip       *big.Float `domo:"Tip"`
+	Fee       big.Float  `domo:"Fee"`
+	Day       Date       `domo:"Day"`
+	Arrival   *time.Time `domo:"Arrival"`
+	Created   time.Time  `domo:"Created,date"`
+	Internal  string     `domo:"-"`
+	unexposed string
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	type embedded struct {
+		ID uint32 `domo:"id"`
+	}
+	type withEmbedded struct {
+		embedded
+		Name string `domo:"name"`
+	}
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    *Schema
+		wantErr bool
+	}{
+		{
+			name: "tagged struct",
+			v:    taggedParty{},
+			want: &Schema{Columns: []Column{
+				{Type: ColumnTypeString, Name: "Friend"},
+				{Type: ColumnTypeLong, Name: "Guests"},
+				{Type: ColumnTypeDouble, Name: "Score"},
+				{Type: ColumnTypeDecimal, Name: "Budget"},
+				{Type: ColumnTypeDecimal, Name: "Tip"},
+				{Type: ColumnTypeDecimal, Name: "Fee"},
+				{Type: ColumnTypeDate, Name: "Day"},
+				{Type: ColumnTypeDateTime, Name: "Arrival"},
+				{Type: ColumnTypeDate, Name: "Created"},
+			}},
+		},
+		{
+			name: "nil pointer with embedded struct",
+			v:    (*withEmbedded)(nil),
+			want: &Schema{Columns: []Column{
+				{Type: ColumnTypeLong, Name: "id"},
+				{Type: ColumnTypeString, Name: "name"},
+			}},
+		},
+		{
+			name: "unsupported field type",
+			v: struct {
+				Attending bool
+			}{},
+			wantErr: true,
+		},
+		{
+			name: "unknown tag type",
+			v: struct {
+				Attending bool `domo:"attending,BOOLEAN"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name: "duplicate column",
+			v: struct {
+				A string `domo:"x"`
+				B string `domo:"x"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name:    "not a struct",
+			v:       "Friend",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SchemaFromStruct(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SchemaFromStruct() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SchemaFromStruct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaFromStruct_RoundTrip(t *testing.T) {
+	schema, err := SchemaFromStruct(taggedParty{})
+	if err != nil {
+		t.Fatalf("SchemaFromStruct() error = %v", err)
+	}
+	guests := int64(3)
+	arrival := time.Date(1777, 4, 30, 18, 15, 0, 0, time.UTC)
+	in := taggedParty{
+		Friend:  "Gauss",
+		Guests:  &guests,
+		Score:   9.5,
+		Day:     Date{Year: 1777, Month: time.April, Day: 30},
+		Arrival: &arrival,
+		Created: time.Date(1700, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	in.Budget.SetFrac64(123456, 100)
+	in.Tip = big.NewFloat(12.5)
+	in.Fee.SetFloat64(0.75)
+
+	var buf bytes.Buffer
+	buf.WriteString("Friend,Guests,Score,Budget,Tip,Fee,Day,Arrival,Created\n")
+	enc, err := NewEncoder(&buf, schema)
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+	if err := enc.Encode(in); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Encoder.Flush() error = %v", err)
+	}
+
+	rows, err := newCSVRows(ioutil.NopCloser(&buf), schema)
+	if err != nil {
+		t.Fatalf("newCSVRows() error = %v", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("Rows.Next() = false, err %v", rows.Err())
+	}
+	var out taggedParty
+	if err := rows.Scan(&out); err != nil {
+		t.Fatalf("Rows.Scan() error = %v", err)
+	}
+	if out.Friend != in.Friend || *out.Guests != guests || out.Score != in.Score || out.Budget.Cmp(&in.Budget) != 0 ||
+		out.Tip == nil || out.Tip.Cmp(in.Tip) != 0 || out.Fee.Cmp(&in.Fee) != 0 ||
+		out.Day != in.Day || !out.Arrival.Equal(arrival) || !out.Created.Equal(in.Created) {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestDate(t *testing.T) {
+	d, err := ParseDate("2016-06-21")
+	if err != nil {
+		t.Fatalf("ParseDate() error = %v", err)
+	}
+	if d != (Date{Year: 2016, Month: time.June, Day: 21}) || d.String() != "2016-06-21" {
+		t.Errorf("ParseDate() = %v", d)
+	}
+	if got := DateOf(d.In(time.UTC)); got != d {
+		t.Errorf("DateOf(In()) = %v, want %v", got, d)
+	}
+	if _, err := ParseDate("21/06/2016"); err == nil {
+		t.Errorf("ParseDate() error = nil")
+	}
+}
+
+func TestEncoder_TagTypeMismatch(t *testing.T) {
+	enc, err := NewEncoder(ioutil.Discard, &Schema{Columns: []Column{{Type: ColumnTypeString, Name: "Day"}}})
+	if err != nil {
+		t.Fatalf("NewEncoder() error = %v", err)
+	}
+	row := struct {
+		Day time.Time `domo:"Day,DATE"`
+	}{}
+	if err := enc.Encode(row); err == nil {
+		t.Errorf("Encoder.Encode() with mismatching tag type error = nil")
+	}
+}
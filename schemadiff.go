@@ -0,0 +1,284 @@
+package domoapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+//SchemaChangeKind classifies a difference between two schemas
+type SchemaChangeKind int
+
+//Kinds of schema changes reported by DiffSchema
+const (
+	ColumnAdded SchemaChangeKind = iota + 1
+	ColumnRemoved
+	ColumnRenamed
+	ColumnTypeChanged
+	ColumnMoved
+)
+
+func (k SchemaChangeKind) String() string {
+	switch k {
+	case ColumnAdded:
+		return "added"
+	case ColumnRemoved:
+		return "removed"
+	case ColumnRenamed:
+		return "renamed"
+	case ColumnTypeChanged:
+		return "type changed"
+	case ColumnMoved:
+		return "moved"
+	}
+	return fmt.Sprintf("SchemaChangeKind(%d)", int(k))
+}
+
+//SchemaChange is a single column difference between the live and the desired schema.
+//Old is the live column and is empty for ColumnAdded, New is the desired column and is empty for ColumnRemoved.
+//Index is the position of the column in the desired schema, or in the live schema for ColumnRemoved.
+//OldIndex is the position of the column in the live schema for ColumnMoved.
+type SchemaChange struct {
+	Kind     SchemaChangeKind
+	Index    int
+	OldIndex int
+	Old      Column
+	New      Column
+}
+
+//Destructive reports whether applying the change can lose data or break cards built on the dataset:
+//removed and renamed columns and type changes other than widening LONG to DOUBLE or DECIMAL, or any type to STRING.
+//Moved columns keep their data but change the column order csv uploads must follow.
+func (c SchemaChange) Destructive() bool {
+	switch c.Kind {
+	case ColumnAdded, ColumnMoved:
+		return false
+	case ColumnTypeChanged:
+		return !isWidening(c.Old.Type, c.New.Type)
+	}
+	return true
+}
+
+func (c SchemaChange) String() string {
+	switch c.Kind {
+	case ColumnAdded:
+		return fmt.Sprintf("column %q (%s) added", c.New.Name, c.New.Type)
+	case ColumnRemoved:
+		return fmt.Sprintf("column %q (%s) removed", c.Old.Name, c.Old.Type)
+	case ColumnRenamed:
+		return fmt.Sprintf("column %q renamed to %q", c.Old.Name, c.New.Name)
+	case ColumnMoved:
+		return fmt.Sprintf("column %q moved from position %d to %d", c.New.Name, c.OldIndex, c.Index)
+	}
+	return fmt.Sprintf("column %q changed from %s to %s", c.New.Name, c.Old.Type, c.New.Type)
+}
+
+func isWidening(from, to string) bool {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if to == ColumnTypeString {
+		return true
+	}
+	return from == ColumnTypeLong && (to == ColumnTypeDouble || to == ColumnTypeDecimal)
+}
+
+//SchemaDiff lists the changes needed to turn a live schema into a desired one
+type SchemaDiff struct {
+	Changes []SchemaChange
+}
+
+//Empty reports whether both schemas are the same
+func (s *SchemaDiff) Empty() bool {
+	return len(s.Changes) == 0
+}
+
+//Destructive returns the changes that can lose data, see SchemaChange.Destructive
+func (s *SchemaDiff) Destructive() []SchemaChange {
+	var changes []SchemaChange
+	for _, c := range s.Changes {
+		if c.Destructive() {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+//DiffSchema compares the live schema of a dataset with the desired one.
+//Columns are matched by name; an unmatched live column and an unmatched desired column
+//at the same position and with the same type are reported as a rename.
+//Csv uploads are positional, so matched columns whose relative order changed are reported as moved;
+//the fewest columns needed to restore the desired order are reported.
+//Column types are compared case-insensitively. A nil schema has no columns.
+func DiffSchema(live, desired *Schema) *SchemaDiff {
+	var liveCols, desiredCols []Column
+	if live != nil {
+		liveCols = live.Columns
+	}
+	if desired != nil {
+		desiredCols = desired.Columns
+	}
+
+	liveIndex := make(map[string]int, len(liveCols))
+	for i, c := range liveCols {
+		if _, ok := liveIndex[c.Name]; !ok {
+			liveIndex[c.Name] = i
+		}
+	}
+
+	diff := &SchemaDiff{}
+	matched := make([]bool, len(liveCols))
+	var added []int
+	var pairs [][2]int //desired and live positions of the columns matched by name
+	for i, c := range desiredCols {
+		j, ok := liveIndex[c.Name]
+		if !ok || matched[j] {
+			added = append(added, i)
+			continue
+		}
+		matched[j] = true
+		pairs = append(pairs, [2]int{i, j})
+		if !strings.EqualFold(liveCols[j].Type, c.Type) {
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: ColumnTypeChanged, Index: i, Old: liveCols[j], New: c})
+		}
+	}
+
+	inOrder := longestIncreasing(pairs)
+	for k, p := range pairs {
+		if !inOrder[k] {
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: ColumnMoved, Index: p[0], OldIndex: p[1], Old: liveCols[p[1]], New: desiredCols[p[0]]})
+		}
+	}
+
+	for _, i := range added {
+		c := desiredCols[i]
+		if i < len(liveCols) && !matched[i] && strings.EqualFold(liveCols[i].Type, c.Type) {
+			matched[i] = true
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: ColumnRenamed, Index: i, Old: liveCols[i], New: c})
+			continue
+		}
+		diff.Changes = append(diff.Changes, SchemaChange{Kind: ColumnAdded, Index: i, New: c})
+	}
+
+	for j, c := range liveCols {
+		if !matched[j] {
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: ColumnRemoved, Index: j, Old: c})
+		}
+	}
+	return diff
+}
+
+//longestIncreasing marks the pairs of a longest subsequence with increasing live positions,
+//the other matched columns changed their relative order
+func longestIncreasing(pairs [][2]int) []bool {
+	length := make([]int, len(pairs))
+	prev := make([]int, len(pairs))
+	best := -1
+	for k := range pairs {
+		length[k], prev[k] = 1, -1
+		for l := 0; l < k; l++ {
+			if pairs[l][1] < pairs[k][1] && length[l]+1 > length[k] {
+				length[k], prev[k] = length[l]+1, l
+			}
+		}
+		if best < 0 || length[k] > length[best] {
+			best = k
+		}
+	}
+	inOrder := make([]bool, len(pairs))
+	for k := best; k >= 0; k = prev[k] {
+		inOrder[k] = true
+	}
+	return inOrder
+}
+
+//SchemaChangeError is returned by UpdateSchema when the diff contains destructive changes that were not allowed
+type SchemaChangeError struct {
+	DatasetID string
+	Changes   []SchemaChange
+}
+
+func (e *SchemaChangeError) Error() string {
+	s := make([]string, len(e.Changes))
+	for i, c := range e.Changes {
+		s[i] = c.String()
+	}
+	return fmt.Sprintf("error: destructive schema changes on dataset %s: %s", e.DatasetID, strings.Join(s, ", "))
+}
+
+//SchemaUpdateOptions controls how UpdateSchema handles destructive changes
+type SchemaUpdateOptions struct {
+	//AllowDestructive applies removed, renamed and narrowed columns too
+	AllowDestructive bool
+	//SkipDestructive applies only the safe changes and keeps the live columns for the destructive ones,
+	//instead of failing with a *SchemaChangeError
+	SkipDestructive bool
+	//DryRun only computes the diff without updating the dataset
+	DryRun bool
+}
+
+//DetectSchemaDrift compares the live schema of a dataset with the desired one
+func (d *DomoAPI) DetectSchemaDrift(ctx context.Context, datasetID string, desired Schema) (*SchemaDiff, error) {
+	ds, err := d.GetDatasetContext(ctx, datasetID)
+	if err != nil {
+		return nil, err
+	}
+	return DiffSchema(ds.Schema, &desired), nil
+}
+
+//UpdateSchema brings the schema of a dataset in line with desired and returns the diff against the live schema.
+//Safe changes are always applied. Destructive changes fail with a *SchemaChangeError before anything is updated,
+//unless opts allows or skips them; skipped changes are still listed in the returned diff.
+//Nothing is updated when the schemas are the same.
+func (d *DomoAPI) UpdateSchema(ctx context.Context, datasetID string, desired Schema, opts SchemaUpdateOptions) (*SchemaDiff, error) {
+	ds, err := d.GetDatasetContext(ctx, datasetID)
+	if err != nil {
+		return nil, err
+	}
+	diff := DiffSchema(ds.Schema, &desired)
+	if diff.Empty() {
+		return diff, nil
+	}
+
+	target := desired
+	if destructive := diff.Destructive(); len(destructive) > 0 && !opts.AllowDestructive {
+		if !opts.SkipDestructive {
+			return diff, &SchemaChangeError{DatasetID: datasetID, Changes: destructive}
+		}
+		target = safeSchema(desired, destructive)
+		if DiffSchema(ds.Schema, &target).Empty() {
+			return diff, nil
+		}
+	}
+	if opts.DryRun {
+		return diff, nil
+	}
+
+	if _, err := d.UpdateDatasetContext(ctx, datasetID, DomoDataset{Schema: &target}); err != nil {
+		return diff, err
+	}
+	return diff, nil
+}
+
+//safeSchema reverts the destructive changes in desired to the live columns
+func safeSchema(desired Schema, destructive []SchemaChange) Schema {
+	cols := append([]Column(nil), desired.Columns...)
+	var removed []SchemaChange
+	for _, c := range destructive {
+		switch c.Kind {
+		case ColumnRenamed, ColumnTypeChanged:
+			cols[c.Index] = c.Old
+		case ColumnRemoved:
+			removed = append(removed, c)
+		}
+	}
+	//removed columns are listed by live position, put them back where they were
+	for _, c := range removed {
+		i := c.Index
+		if i > len(cols) {
+			i = len(cols)
+		}
+		cols = append(cols, Column{})
+		copy(cols[i+1:], cols[i:])
+		cols[i] = c.Old
+	}
+	return Schema{Columns: cols}
+}
@@ -0,0 +1,210 @@
+package domoapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+func TestDiffSchema(t *testing.T) {
+	live := &Schema{Columns: []Column{
+		{Name: "Friend", Type: ColumnTypeString},
+		{Name: "Attending", Type: ColumnTypeLong},
+		{Name: "Food", Type: ColumnTypeString},
+	}}
+	tests := []struct {
+		name            string
+		desired         *Schema
+		want            []SchemaChange
+		wantDestructive int
+	}{
+		{
+			name:    "same schema",
+			desired: &Schema{Columns: []Column{{Name: "Friend", Type: "string"}, {Name: "Attending", Type: "LONG"}, {Name: "Food", Type: "STRING"}}},
+		},
+		{
+			name: "added column",
+			desired: &Schema{Columns: append(append([]Column(nil), live.Columns...),
+				Column{Name: "Drink", Type: ColumnTypeString})},
+			want: []SchemaChange{{Kind: ColumnAdded, Index: 3, New: Column{Name: "Drink", Type: ColumnTypeString}}},
+		},
+		{
+			name:            "removed column",
+			desired:         &Schema{Columns: live.Columns[:2]},
+			want:            []SchemaChange{{Kind: ColumnRemoved, Index: 2, Old: Column{Name: "Food", Type: ColumnTypeString}}},
+			wantDestructive: 1,
+		},
+		{
+			name:    "renamed column",
+			desired: &Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Attending", Type: ColumnTypeLong}, {Name: "Dish", Type: ColumnTypeString}}},
+			want: []SchemaChange{{Kind: ColumnRenamed, Index: 2,
+				Old: Column{Name: "Food", Type: ColumnTypeString}, New: Column{Name: "Dish", Type: ColumnTypeString}}},
+			wantDestructive: 1,
+		},
+		{
+			name:    "widened and narrowed types",
+			desired: &Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeLong}, {Name: "Attending", Type: ColumnTypeDouble}, {Name: "Food", Type: ColumnTypeString}}},
+			want: []SchemaChange{
+				{Kind: ColumnTypeChanged, Index: 0, Old: Column{Name: "Friend", Type: ColumnTypeString}, New: Column{Name: "Friend", Type: ColumnTypeLong}},
+				{Kind: ColumnTypeChanged, Index: 1, Old: Column{Name: "Attending", Type: ColumnTypeLong}, New: Column{Name: "Attending", Type: ColumnTypeDouble}},
+			},
+			wantDestructive: 1,
+		},
+		{
+			name:    "reordered columns",
+			desired: &Schema{Columns: []Column{{Name: "Attending", Type: ColumnTypeLong}, {Name: "Friend", Type: ColumnTypeString}, {Name: "Food", Type: ColumnTypeString}}},
+			want: []SchemaChange{{Kind: ColumnMoved, Index: 1, OldIndex: 0,
+				Old: Column{Name: "Friend", Type: ColumnTypeString}, New: Column{Name: "Friend", Type: ColumnTypeString}}},
+		},
+		{
+			name:    "column moved to the end",
+			desired: &Schema{Columns: []Column{{Name: "Attending", Type: ColumnTypeLong}, {Name: "Food", Type: ColumnTypeString}, {Name: "Friend", Type: ColumnTypeString}}},
+			want: []SchemaChange{{Kind: ColumnMoved, Index: 2, OldIndex: 0,
+				Old: Column{Name: "Friend", Type: ColumnTypeString}, New: Column{Name: "Friend", Type: ColumnTypeString}}},
+		},
+		{
+			name:    "different type at same position is not a rename",
+			desired: &Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Attending", Type: ColumnTypeLong}, {Name: "Guests", Type: ColumnTypeLong}}},
+			want: []SchemaChange{
+				{Kind: ColumnAdded, Index: 2, New: Column{Name: "Guests", Type: ColumnTypeLong}},
+				{Kind: ColumnRemoved, Index: 2, Old: Column{Name: "Food", Type: ColumnTypeString}},
+			},
+			wantDestructive: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffSchema(live, tt.desired)
+			if !reflect.DeepEqual(got.Changes, tt.want) {
+				t.Errorf("DiffSchema() = %+v, want %+v", got.Changes, tt.want)
+			}
+			if got.Empty() != (len(tt.want) == 0) {
+				t.Errorf("SchemaDiff.Empty() = %v", got.Empty())
+			}
+			if n := len(got.Destructive()); n != tt.wantDestructive {
+				t.Errorf("SchemaDiff.Destructive() = %d changes, want %d", n, tt.wantDestructive)
+			}
+		})
+	}
+}
+
+func TestDomoAPI_UpdateSchema(t *testing.T) {
+	//live schema of createDatasetOKJson is Friend STRING, Attending STRING
+	tests := []struct {
+		name       string
+		desired    Schema
+		opts       SchemaUpdateOptions
+		wantUpdate *Schema
+		wantErr    bool
+	}{
+		{
+			name:    "no changes",
+			desired: Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Attending", Type: ColumnTypeString}}},
+		},
+		{
+			name:       "safe change is applied",
+			desired:    Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Attending", Type: ColumnTypeString}, {Name: "Food", Type: ColumnTypeString}}},
+			wantUpdate: &Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Attending", Type: ColumnTypeString}, {Name: "Food", Type: ColumnTypeString}}},
+		},
+		{
+			name:       "reorder is applied",
+			desired:    Schema{Columns: []Column{{Name: "Attending", Type: ColumnTypeString}, {Name: "Friend", Type: ColumnTypeString}}},
+			wantUpdate: &Schema{Columns: []Column{{Name: "Attending", Type: ColumnTypeString}, {Name: "Friend", Type: ColumnTypeString}}},
+		},
+		{
+			name:    "destructive change is refused",
+			desired: Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Food", Type: ColumnTypeString}}},
+			wantErr: true,
+		},
+		{
+			name:       "destructive change is allowed",
+			desired:    Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Attending", Type: ColumnTypeLong}}},
+			opts:       SchemaUpdateOptions{AllowDestructive: true},
+			wantUpdate: &Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Attending", Type: ColumnTypeLong}}},
+		},
+		{
+			name:       "destructive change is skipped",
+			desired:    Schema{Columns: []Column{{Name: "Attending", Type: ColumnTypeLong}, {Name: "Food", Type: ColumnTypeString}}},
+			opts:       SchemaUpdateOptions{SkipDestructive: true},
+			wantUpdate: &Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Attending", Type: ColumnTypeString}, {Name: "Food", Type: ColumnTypeString}}},
+		},
+		{
+			name:    "only destructive changes are skipped",
+			desired: Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}}},
+			opts:    SchemaUpdateOptions{SkipDestructive: true},
+		},
+		{
+			name:    "dry run",
+			desired: Schema{Columns: []Column{{Name: "Friend", Type: ColumnTypeString}, {Name: "Attending", Type: ColumnTypeString}, {Name: "Food", Type: ColumnTypeString}}},
+			opts:    SchemaUpdateOptions{DryRun: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rmock := mocks.NewMockRequestHandlerService(ctrl)
+			get := rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(createDatasetOKJson, 200), nil)
+			if tt.wantUpdate != nil {
+				rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					if req.Method != http.MethodPut {
+						t.Errorf("request method = %v, want PUT", req.Method)
+					}
+					body, _ := ioutil.ReadAll(req.Body)
+					var sent DomoDataset
+					_ = json.Unmarshal(body, &sent)
+					if !reflect.DeepEqual(sent.Schema, tt.wantUpdate) {
+						t.Errorf("updated schema = %+v, want %+v", sent.Schema, tt.wantUpdate)
+					}
+					return getMockResponse(createDatasetOKJson, 200), nil
+				}).After(get)
+			}
+			domoAPI := &DomoAPI{
+				requestHandlerService: rmock,
+				tokens:                &tokenSource{token: &sampleToken},
+			}
+
+			diff, err := domoAPI.UpdateSchema(context.Background(), "4405ff58-1957-45f0-82bd-914d989a3ea3", tt.desired, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DomoAPI.UpdateSchema() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var changeErr *SchemaChangeError
+			if tt.wantErr && !errors.As(err, &changeErr) {
+				t.Errorf("DomoAPI.UpdateSchema() error = %T, want *SchemaChangeError", err)
+			}
+			if diff == nil {
+				t.Errorf("DomoAPI.UpdateSchema() returned no diff")
+			}
+		})
+	}
+}
+
+func TestDomoAPI_DetectSchemaDrift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	//live schema of createDatasetOKJson is Friend STRING, Attending STRING
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(createDatasetOKJson, 200), nil)
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	desired := Schema{Columns: []Column{{Name: "Attending", Type: ColumnTypeString}, {Name: "Friend", Type: ColumnTypeString}}}
+	diff, err := domoAPI.DetectSchemaDrift(context.Background(), "4405ff58-1957-45f0-82bd-914d989a3ea3", desired)
+	if err != nil {
+		t.Fatalf("DomoAPI.DetectSchemaDrift() error = %v", err)
+	}
+	if diff.Empty() || len(diff.Changes) != 1 || diff.Changes[0].Kind != ColumnMoved {
+		t.Errorf("DomoAPI.DetectSchemaDrift() = %+v, want a moved column", diff.Changes)
+	}
+}
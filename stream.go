@@ -0,0 +1,340 @@
+package domoapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//Stream loads data into a dataset in executions made of separately uploaded parts,
+//the way to upload large datasets
+type Stream struct {
+	ID           int          `json:"id,omitempty"`
+	DataSet      *DomoDataset `json:"dataSet,omitempty"`
+	UpdateMethod string       `json:"updateMethod,omitempty"`
+	CreatedAt    *time.Time   `json:"createdAt,omitempty"`
+	ModifiedAt   *time.Time   `json:"modifiedAt,omitempty"`
+}
+
+//Stream update methods, whether a committed execution replaces the dataset's data or is appended to it
+const (
+	StreamUpdateAppend  = "APPEND"
+	StreamUpdateReplace = "REPLACE"
+)
+
+//Execution is a single load of data into a stream's dataset
+type Execution struct {
+	ID            int        `json:"id"`
+	CurrentState  string     `json:"currentState,omitempty"`
+	UpdateMethod  string     `json:"updateMethod,omitempty"`
+	UploadedBytes int64      `json:"uploadedBytes,omitempty"`
+	UploadedParts int        `json:"uploadedParts,omitempty"`
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	EndedAt       *time.Time `json:"endedAt,omitempty"`
+	CreatedAt     *time.Time `json:"createdAt,omitempty"`
+	ModifiedAt    *time.Time `json:"modifiedAt,omitempty"`
+}
+
+//Execution states
+const (
+	ExecutionStateActive  = "ACTIVE"
+	ExecutionStateSuccess = "SUCCESS"
+	ExecutionStateError   = "ERROR"
+	ExecutionStateAborted = "ABORTED"
+)
+
+//Done reports whether the execution is over, whatever its outcome
+func (e *Execution) Done() bool {
+	return e.CurrentState == ExecutionStateSuccess || e.CurrentState == ExecutionStateError || e.CurrentState == ExecutionStateAborted
+}
+
+//maxStreamPageSize is the largest number of streams Domo returns per page
+const maxStreamPageSize = 500
+
+//DefaultPartConcurrency is the number of parts UploadParts uploads at the same time by default
+const DefaultPartConcurrency = 4
+
+func streamPath(streamID int) string {
+	return "/v1/streams/" + strconv.Itoa(streamID)
+}
+
+func executionPath(streamID, executionID int) string {
+	return streamPath(streamID) + "/executions/" + strconv.Itoa(executionID)
+}
+
+//CreateStream creates a dataset together with the stream loading it
+func (d *DomoAPI) CreateStream(ctx context.Context, dds DomoDataset, updateMethod string) (*Stream, error) {
+	req, err := d.newJSONRequest(WithIdempotent(ctx, false), http.MethodPost, "/v1/streams", Stream{DataSet: &dds, UpdateMethod: updateMethod})
+	if err != nil {
+		return nil, err
+	}
+
+	var s *Stream
+	if err := d.doJSON(req, &s, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+//GetStream gets a stream and its dataset
+func (d *DomoAPI) GetStream(ctx context.Context, streamID int) (*Stream, error) {
+	req, err := d.newRequest(ctx, http.MethodGet, streamPath(streamID), nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var s *Stream
+	if err := d.doJSON(req, &s, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+//ListStreams lists all streams of the domo instance
+func (d *DomoAPI) ListStreams(ctx context.Context) ([]Stream, error) {
+	var streams []Stream
+	for offset := 0; ; offset += maxStreamPageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		path := fmt.Sprintf("/v1/streams?limit=%d&offset=%d", maxStreamPageSize, offset)
+		req, err := d.newRequest(ctx, http.MethodGet, path, nil, "application/json")
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Stream
+		if err := d.doJSON(req, &page, http.StatusOK); err != nil {
+			return nil, err
+		}
+		streams = append(streams, page...)
+		if len(page) < maxStreamPageSize {
+			return streams, nil
+		}
+	}
+}
+
+//FindStreamByDatasetID returns the stream loading the given dataset, or nil if there is none
+func (d *DomoAPI) FindStreamByDatasetID(ctx context.Context, datasetID string) (*Stream, error) {
+	if datasetID == "" {
+		return nil, fmt.Errorf("error: missing datasetID")
+	}
+	path := "/v1/streams/search?q=" + url.QueryEscape("dataSource.id:"+datasetID)
+	req, err := d.newRequest(ctx, http.MethodGet, path, nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var streams []Stream
+	if err := d.doJSON(req, &streams, http.StatusOK); err != nil {
+		return nil, err
+	}
+	if len(streams) == 0 {
+		return nil, nil
+	}
+	return &streams[0], nil
+}
+
+//UpdateStream changes the update method of a stream
+func (d *DomoAPI) UpdateStream(ctx context.Context, streamID int, updateMethod string) (*Stream, error) {
+	req, err := d.newJSONRequest(ctx, http.MethodPatch, streamPath(streamID), Stream{UpdateMethod: updateMethod})
+	if err != nil {
+		return nil, err
+	}
+
+	var s *Stream
+	if err := d.doJSON(req, &s, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+//DeleteStream deletes a stream. The dataset it loads is kept.
+func (d *DomoAPI) DeleteStream(ctx context.Context, streamID int) error {
+	req, err := d.newRequest(ctx, http.MethodDelete, streamPath(streamID), nil, "")
+	if err != nil {
+		return err
+	}
+	return d.doJSON(req, nil, http.StatusNoContent)
+}
+
+//CreateExecution starts a new load of a stream. Upload its parts, then commit or abort it.
+func (d *DomoAPI) CreateExecution(ctx context.Context, streamID int) (*Execution, error) {
+	req, err := d.newRequest(WithIdempotent(ctx, false), http.MethodPost, streamPath(streamID)+"/executions", nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var e *Execution
+	if err := d.doJSON(req, &e, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+//GetExecution gets the status of an execution
+func (d *DomoAPI) GetExecution(ctx context.Context, streamID, executionID int) (*Execution, error) {
+	req, err := d.newRequest(ctx, http.MethodGet, executionPath(streamID, executionID), nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var e *Execution
+	if err := d.doJSON(req, &e, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+//ListExecutions lists a page of the executions of a stream, limit is at most 500
+func (d *DomoAPI) ListExecutions(ctx context.Context, streamID int, limit, offset int) ([]Execution, error) {
+	path := fmt.Sprintf("%s/executions?limit=%d&offset=%d", streamPath(streamID), limit, offset)
+	req, err := d.newRequest(ctx, http.MethodGet, path, nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var executions []Execution
+	if err := d.doJSON(req, &executions, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+//PartOptions configures the upload of execution parts
+type PartOptions struct {
+	//Gzip compresses the parts before they are uploaded
+	Gzip bool
+	//Concurrency is the number of parts UploadParts uploads at the same time, DefaultPartConcurrency by default
+	Concurrency int
+}
+
+//UploadPart uploads the csv data read from r as part partID of an execution, without buffering it.
+//Parts are numbered from 1, the data must not contain a header line. Uploading a part again replaces it.
+//Requests with a streamed body are never retried, see ImportDataset.
+func (d *DomoAPI) UploadPart(ctx context.Context, streamID, executionID, partID int, r io.Reader, opts PartOptions) error {
+	if partID < 1 {
+		return fmt.Errorf("error: invalid part %d, parts are numbered from 1", partID)
+	}
+	body := r
+	if opts.Gzip {
+		zr := gzipReader(r)
+		defer zr.Close()
+		body = zr
+	}
+	return d.uploadPart(ctx, streamID, executionID, partID, body, opts.Gzip)
+}
+
+func (d *DomoAPI) uploadPart(ctx context.Context, streamID, executionID, partID int, body io.Reader, gzipped bool) error {
+	path := executionPath(streamID, executionID) + "/part/" + strconv.Itoa(partID)
+	req, err := d.newRequest(ctx, http.MethodPut, path, body, "text/csv")
+	if err != nil {
+		return err
+	}
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return d.doJSON(req, nil, http.StatusOK)
+}
+
+//UploadParts uploads parts[i] as part i+1 of an execution, opts.Concurrency parts at a time.
+//Each part is held in memory, compressed first when opts.Gzip is set, so failed uploads can be retried.
+//The first error stops the remaining uploads and is returned; the execution is left open.
+func (d *DomoAPI) UploadParts(ctx context.Context, streamID, executionID int, parts [][]byte, opts PartOptions) error {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultPartConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	for w := 0; w < concurrency && w < len(parts); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				data := parts[i]
+				if opts.Gzip {
+					var err error
+					if data, err = gzipBytes(data); err != nil {
+						fail(err)
+						continue
+					}
+				}
+				if err := d.uploadPart(ctx, streamID, executionID, i+1, bytes.NewReader(data), opts.Gzip); err != nil {
+					fail(fmt.Errorf("error: part %d - %w", i+1, err))
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range parts {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+//CommitExecution ends an execution and loads its parts into the dataset
+func (d *DomoAPI) CommitExecution(ctx context.Context, streamID, executionID int) (*Execution, error) {
+	return d.endExecution(ctx, streamID, executionID, "commit")
+}
+
+//AbortExecution ends an execution and drops its parts
+func (d *DomoAPI) AbortExecution(ctx context.Context, streamID, executionID int) (*Execution, error) {
+	return d.endExecution(ctx, streamID, executionID, "abort")
+}
+
+func (d *DomoAPI) endExecution(ctx context.Context, streamID, executionID int, action string) (*Execution, error) {
+	req, err := d.newRequest(ctx, http.MethodPut, executionPath(streamID, executionID)+"/"+action, nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var e *Execution
+	if err := d.doJSON(req, &e, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
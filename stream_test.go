@@ -0,0 +1,306 @@
+package domoapi
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+const (
+	streamJSON = `{
+		"id": 42,
+		"dataSet": {
+			"id": "4405ff58-1957-45f0-82bd-914d989a3ea3",
+			"name": "Leonhard Euler Party",
+			"schema": {"columns": [{"type": "STRING", "name": "Friend"}, {"type": "STRING", "name": "Attending"}]}
+		},
+		"updateMethod": "APPEND",
+		"createdAt": "2016-06-21T17:20:36Z",
+		"modifiedAt": "2016-06-21T17:20:36Z"
+	}`
+	executionJSON = `{
+		"id": 7,
+		"startedAt": "2016-06-21T17:20:36Z",
+		"currentState": "ACTIVE",
+		"createdAt": "2016-06-21T17:20:36Z",
+		"modifiedAt": "2016-06-21T17:20:36Z"
+	}`
+	committedExecutionJSON = `{"id": 7, "currentState": "SUCCESS"}`
+)
+
+func TestDomoAPI_streamMethods(t *testing.T) {
+	checkStream := func(t *testing.T, s *Stream) {
+		t.Helper()
+		if s == nil || s.ID != 42 || s.DataSet == nil || s.DataSet.ID != "4405ff58-1957-45f0-82bd-914d989a3ea3" || s.UpdateMethod != StreamUpdateAppend {
+			t.Errorf("stream = %+v", s)
+		}
+	}
+	checkExecution := func(t *testing.T, e *Execution, state string) {
+		t.Helper()
+		if e == nil || e.ID != 7 || e.CurrentState != state || e.Done() != (state != ExecutionStateActive) {
+			t.Errorf("execution = %+v, want state %v", e, state)
+		}
+	}
+	tests := []struct {
+		name    string
+		request mockedRequest
+		run     func(t *testing.T, d *DomoAPI)
+	}{
+		{
+			name: "create stream",
+			request: mockedRequest{
+				method:     http.MethodPost,
+				uri:        "/v1/streams",
+				body:       `{"dataSet":{"name":"Leonhard Euler Party"},"updateMethod":"APPEND"}`,
+				response:   streamJSON,
+				statusCode: 201,
+			},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.CreateStream(context.Background(), DomoDataset{Name: "Leonhard Euler Party"}, StreamUpdateAppend)
+				if err != nil {
+					t.Fatalf("DomoAPI.CreateStream() error = %v", err)
+				}
+				checkStream(t, got)
+			},
+		},
+		{
+			name:    "get stream",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/streams/42", response: streamJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.GetStream(context.Background(), 42)
+				if err != nil {
+					t.Fatalf("DomoAPI.GetStream() error = %v", err)
+				}
+				checkStream(t, got)
+			},
+		},
+		{
+			name:    "list streams",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/streams?limit=500&offset=0", response: "[" + streamJSON + "]", statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.ListStreams(context.Background())
+				if err != nil {
+					t.Fatalf("DomoAPI.ListStreams() error = %v", err)
+				}
+				if len(got) != 1 {
+					t.Fatalf("DomoAPI.ListStreams() returned %d streams", len(got))
+				}
+				checkStream(t, &got[0])
+			},
+		},
+		{
+			name: "find stream by dataset",
+			request: mockedRequest{
+				method:     http.MethodGet,
+				uri:        "/v1/streams/search?q=dataSource.id%3A4405ff58-1957-45f0-82bd-914d989a3ea3",
+				response:   "[" + streamJSON + "]",
+				statusCode: 200,
+			},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.FindStreamByDatasetID(context.Background(), "4405ff58-1957-45f0-82bd-914d989a3ea3")
+				if err != nil {
+					t.Fatalf("DomoAPI.FindStreamByDatasetID() error = %v", err)
+				}
+				checkStream(t, got)
+			},
+		},
+		{
+			name:    "no stream for dataset",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/streams/search?q=dataSource.id%3Aunknown", response: "[]", statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.FindStreamByDatasetID(context.Background(), "unknown")
+				if err != nil || got != nil {
+					t.Errorf("DomoAPI.FindStreamByDatasetID() = %+v, %v, want nil", got, err)
+				}
+			},
+		},
+		{
+			name: "update stream",
+			request: mockedRequest{
+				method:     http.MethodPatch,
+				uri:        "/v1/streams/42",
+				body:       `{"updateMethod":"REPLACE"}`,
+				response:   streamJSON,
+				statusCode: 200,
+			},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.UpdateStream(context.Background(), 42, StreamUpdateReplace)
+				if err != nil {
+					t.Fatalf("DomoAPI.UpdateStream() error = %v", err)
+				}
+				checkStream(t, got)
+			},
+		},
+		{
+			name:    "delete stream",
+			request: mockedRequest{method: http.MethodDelete, uri: "/v1/streams/42", statusCode: 204},
+			run: func(t *testing.T, d *DomoAPI) {
+				if err := d.DeleteStream(context.Background(), 42); err != nil {
+					t.Errorf("DomoAPI.DeleteStream() error = %v", err)
+				}
+			},
+		},
+		{
+			name:    "create execution",
+			request: mockedRequest{method: http.MethodPost, uri: "/v1/streams/42/executions", response: executionJSON, statusCode: 201},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.CreateExecution(context.Background(), 42)
+				if err != nil {
+					t.Fatalf("DomoAPI.CreateExecution() error = %v", err)
+				}
+				checkExecution(t, got, ExecutionStateActive)
+			},
+		},
+		{
+			name:    "get execution",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/streams/42/executions/7", response: executionJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.GetExecution(context.Background(), 42, 7)
+				if err != nil {
+					t.Fatalf("DomoAPI.GetExecution() error = %v", err)
+				}
+				checkExecution(t, got, ExecutionStateActive)
+			},
+		},
+		{
+			name:    "list executions",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/streams/42/executions?limit=50&offset=0", response: "[" + executionJSON + "]", statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.ListExecutions(context.Background(), 42, 50, 0)
+				if err != nil {
+					t.Fatalf("DomoAPI.ListExecutions() error = %v", err)
+				}
+				if len(got) != 1 {
+					t.Fatalf("DomoAPI.ListExecutions() returned %d executions", len(got))
+				}
+				checkExecution(t, &got[0], ExecutionStateActive)
+			},
+		},
+		{
+			name:    "upload part",
+			request: mockedRequest{method: http.MethodPut, uri: "/v1/streams/42/executions/7/part/1", statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				if err := d.UploadPart(context.Background(), 42, 7, 1, strings.NewReader("Euler,TRUE\n"), PartOptions{}); err != nil {
+					t.Errorf("DomoAPI.UploadPart() error = %v", err)
+				}
+			},
+		},
+		{
+			name:    "commit execution",
+			request: mockedRequest{method: http.MethodPut, uri: "/v1/streams/42/executions/7/commit", response: committedExecutionJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.CommitExecution(context.Background(), 42, 7)
+				if err != nil {
+					t.Fatalf("DomoAPI.CommitExecution() error = %v", err)
+				}
+				checkExecution(t, got, ExecutionStateSuccess)
+			},
+		},
+		{
+			name:    "abort execution",
+			request: mockedRequest{method: http.MethodPut, uri: "/v1/streams/42/executions/7/abort", response: `{"id": 7, "currentState": "ABORTED"}`, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.AbortExecution(context.Background(), 42, 7)
+				if err != nil {
+					t.Fatalf("DomoAPI.AbortExecution() error = %v", err)
+				}
+				checkExecution(t, got, ExecutionStateAborted)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tt.run(t, newMockedDomoAPI(t, ctrl, tt.request))
+		})
+	}
+}
+
+func TestDomoAPI_UploadPart_invalid(t *testing.T) {
+	domoAPI := &DomoAPI{tokens: &tokenSource{token: &sampleToken}}
+	if err := domoAPI.UploadPart(context.Background(), 42, 7, 0, strings.NewReader(""), PartOptions{}); err == nil {
+		t.Errorf("DomoAPI.UploadPart() of part 0 should fail")
+	}
+}
+
+func TestDomoAPI_UploadParts(t *testing.T) {
+	parts := [][]byte{
+		[]byte("Euler,TRUE\n"),
+		[]byte("Gauss,FALSE\n"),
+		[]byte("Fermat,TRUE\n"),
+		[]byte("Noether,TRUE\n"),
+		[]byte("Riemann,FALSE\n"),
+	}
+	tests := []struct {
+		name    string
+		opts    PartOptions
+		failing string
+		wantErr bool
+	}{
+		{name: "plain", opts: PartOptions{Concurrency: 2}},
+		{name: "gzip", opts: PartOptions{Gzip: true}},
+		{name: "failed part", opts: PartOptions{Concurrency: 1}, failing: "/v1/streams/42/executions/7/part/2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var mu sync.Mutex
+			uploaded := make(map[string]string)
+			rmock := mocks.NewMockRequestHandlerService(ctrl)
+			rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == tt.failing {
+					return getMockResponse(errorJSON, 500), nil
+				}
+				body := req.Body
+				if req.Header.Get("Content-Encoding") == "gzip" {
+					zr, err := gzip.NewReader(req.Body)
+					if err != nil {
+						t.Errorf("part is not gzipped: %v", err)
+						return getMockResponse(errorJSON, 400), nil
+					}
+					body = zr
+				}
+				data, _ := ioutil.ReadAll(body)
+				mu.Lock()
+				uploaded[req.URL.Path] = string(data)
+				mu.Unlock()
+				return getMockResponse("", 200), nil
+			}).AnyTimes()
+			domoAPI := &DomoAPI{
+				requestHandlerService: rmock,
+				tokens:                &tokenSource{token: &sampleToken},
+			}
+
+			err := domoAPI.UploadParts(context.Background(), 42, 7, parts, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DomoAPI.UploadParts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), "part 2") {
+					t.Errorf("DomoAPI.UploadParts() error = %v, want part 2", err)
+				}
+				if len(uploaded) > 2 {
+					t.Errorf("uploaded %d parts after the failure", len(uploaded))
+				}
+				return
+			}
+			for i, p := range parts {
+				path := "/v1/streams/42/executions/7/part/" + string(rune('1'+i))
+				if uploaded[path] != string(p) {
+					t.Errorf("%s = %q, want %q", path, uploaded[path], p)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package domoapi
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	dateType     = reflect.TypeOf(Date{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+)
+
+//structField is a struct field mapped to a domo column
+type structField struct {
+	//name is the column name, taken from the `domo` tag or the field name
+	name string
+	//tagged is set when the name comes from a `domo` tag
+	tagged bool
+	//columnType is the domo column type given in the `domo` tag, if any
+	columnType string
+	index      []int
+	typ        reflect.Type
+}
+
+//structFieldCache maps a struct type to its []structField
+var structFieldCache sync.Map
+
+//typeFields returns the exported fields of struct type t which map to domo columns.
+//The `domo:"name,type"` tag names the column and optionally its domo type, `domo:"-"` skips the field
+//and embedded structs are flattened.
+func typeFields(t reflect.Type) []structField {
+	if fields, ok := structFieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	fields := collectFields(t, nil)
+	structFieldCache.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("domo")
+		if tag == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		if f.Anonymous && tag == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				//a nil pointer to an unexported struct cannot be allocated by Scan, ignore it like encoding/json
+				if f.PkgPath != "" {
+					continue
+				}
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !isValueStruct(ft) {
+				fields = append(fields, collectFields(ft, fieldIndex)...)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		tagName, columnType := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			tagName, columnType = tag[:i], strings.ToUpper(strings.TrimSpace(tag[i+1:]))
+		}
+		if tagName != "" {
+			name = tagName
+		}
+		fields = append(fields, structField{
+			name:       name,
+			tagged:     tagName != "",
+			columnType: columnType,
+			index:      fieldIndex,
+			typ:        f.Type,
+		})
+	}
+	return fields
+}
+
+//isValueStruct reports whether t is a struct type holding a single column value
+func isValueStruct(t reflect.Type) bool {
+	return t == timeType || t == dateType || t == bigRatType || t == bigFloatType
+}
+
+//fieldByName finds the field for column name, preferring an exact match over a case-insensitive one
+func fieldByName(fields []structField, name string) (structField, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
+
+//fieldByIndex returns the field of v, allocating nil embedded struct pointers on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+//assignValue stores a decoded column value (nil, string, int64, float64, *big.Rat or time.Time) into dst
+func assignValue(dst reflect.Value, v interface{}) error {
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr && dst.Type() != reflect.PtrTo(bigRatType) {
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignValue(elem.Elem(), v); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+	if dst.Kind() == reflect.Interface {
+		dst.Set(reflect.ValueOf(v))
+		return nil
+	}
+
+	switch dst.Type() {
+	case bigRatType, reflect.PtrTo(bigRatType):
+		r, err := toRat(v)
+		if err != nil {
+			return err
+		}
+		if dst.Kind() == reflect.Ptr {
+			dst.Set(reflect.ValueOf(r))
+		} else {
+			dst.Set(reflect.ValueOf(r).Elem())
+		}
+		return nil
+	case bigFloatType:
+		r, err := toRat(v)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(new(big.Float).SetRat(r)).Elem())
+		return nil
+	case timeType:
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to time.Time", v)
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	case dateType:
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("cannot assign %T to Date", v)
+		}
+		dst.Set(reflect.ValueOf(DateOf(t)))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		switch x := v.(type) {
+		case string:
+			dst.SetString(x)
+		case *big.Rat:
+			dst.SetString(x.RatString())
+		default:
+			dst.SetString(fmt.Sprint(x))
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := v.(int64)
+		if !ok || dst.OverflowInt(n) {
+			return fmt.Errorf("cannot assign %T %v to %s", v, v, dst.Type())
+		}
+		dst.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := v.(int64)
+		if !ok || n < 0 || dst.OverflowUint(uint64(n)) {
+			return fmt.Errorf("cannot assign %T %v to %s", v, v, dst.Type())
+		}
+		dst.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch x := v.(type) {
+		case float64:
+			f = x
+		case int64:
+			f = float64(x)
+		case *big.Rat:
+			f, _ = x.Float64()
+		default:
+			return fmt.Errorf("cannot assign %T to %s", v, dst.Type())
+		}
+		dst.SetFloat(f)
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.Type().AssignableTo(dst.Type()) {
+		return fmt.Errorf("cannot assign %T to %s", v, dst.Type())
+	}
+	dst.Set(rv)
+	return nil
+}
+
+func toRat(v interface{}) (*big.Rat, error) {
+	switch x := v.(type) {
+	case *big.Rat:
+		return x, nil
+	case int64:
+		return new(big.Rat).SetInt64(x), nil
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(x) == nil {
+			return nil, fmt.Errorf("cannot assign %v to big.Rat", x)
+		}
+		return r, nil
+	}
+	return nil, fmt.Errorf("cannot assign %T to big.Rat", v)
+}
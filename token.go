@@ -0,0 +1,101 @@
+package domoapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//tokenRefreshWindow is how long before ExpiresAt a cached token gets refreshed
+const tokenRefreshWindow = time.Minute
+
+//Token is domo oauth access token
+type Token struct {
+	AccessToken string `json:"access_token,omitempty"`
+	ExpiresIn   int    `json:"expires_in,omitempty"`
+	ExpiresAt   time.Time
+}
+
+//valid reports whether the token can still be used for at least the refresh window
+func (t *Token) valid() bool {
+	return t != nil && t.AccessToken != "" && time.Now().Add(tokenRefreshWindow).Before(t.ExpiresAt)
+}
+
+//tokenSource lazily fetches a client credentials token and caches it until shortly before it expires.
+//It is safe for concurrent use.
+type tokenSource struct {
+	mu    sync.Mutex
+	token *Token
+	fetch func(ctx context.Context) (*Token, error)
+}
+
+func newTokenSource(fetch func(ctx context.Context) (*Token, error)) *tokenSource {
+	return &tokenSource{fetch: fetch}
+}
+
+//Token returns the cached token, fetching a new one when there is none or it is about to expire
+func (s *tokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.valid() {
+		return s.token, nil
+	}
+	token, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+//invalidate drops the cached token if it is still the given one, so the next call to Token fetches a new one.
+//Comparing with the rejected token keeps concurrent callers from throwing away a token that was just refreshed.
+func (s *tokenSource) invalidate(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && s.token.AccessToken == accessToken {
+		s.token = nil
+	}
+}
+
+//AccessToken returns the access token used by DomoAPI, creating or refreshing it when needed
+func (d *DomoAPI) AccessToken() (*Token, error) {
+	return d.AccessTokenContext(context.Background())
+}
+
+//AccessTokenContext is AccessToken with a context
+func (d *DomoAPI) AccessTokenContext(ctx context.Context) (*Token, error) {
+	return d.tokens.Token(ctx)
+}
+
+//do authorizes req with the cached access token and sends it within the request's context.
+//When domo rejects the token with 401 the token is refreshed and the request is sent once more,
+//provided its body can be replayed.
+func (d *DomoAPI) do(req *http.Request) (*http.Response, error) {
+	token, err := d.tokens.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "bearer "+token.AccessToken)
+
+	resp, err := d.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	retry, ok := rewind(req)
+	if !ok {
+		return resp, nil
+	}
+	drainAndClose(resp.Body)
+
+	d.tokens.invalidate(token.AccessToken)
+	token, err = d.tokens.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	retry.Header.Set("Authorization", "bearer "+token.AccessToken)
+	return d.send(retry)
+}
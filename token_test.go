@@ -0,0 +1,183 @@
+package domoapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+func TestTokenSource_Token(t *testing.T) {
+	tests := []struct {
+		name      string
+		cached    *Token
+		fetchErr  error
+		wantToken string
+		wantFetch int
+		wantErr   bool
+	}{
+		{
+			name:      "fetch when empty",
+			wantToken: "fresh",
+			wantFetch: 1,
+		},
+		{
+			name:      "reuse valid token",
+			cached:    &Token{AccessToken: "cached", ExpiresAt: time.Now().Add(time.Hour)},
+			wantToken: "cached",
+		},
+		{
+			name:      "refresh shortly before expiry",
+			cached:    &Token{AccessToken: "cached", ExpiresAt: time.Now().Add(tokenRefreshWindow / 2)},
+			wantToken: "fresh",
+			wantFetch: 1,
+		},
+		{
+			name:      "fetch error",
+			fetchErr:  fmt.Errorf("boom"),
+			wantFetch: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched := 0
+			s := newTokenSource(func(ctx context.Context) (*Token, error) {
+				fetched++
+				if tt.fetchErr != nil {
+					return nil, tt.fetchErr
+				}
+				return &Token{AccessToken: "fresh", ExpiresAt: time.Now().Add(time.Hour)}, nil
+			})
+			s.token = tt.cached
+
+			got, err := s.Token(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("tokenSource.Token() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.AccessToken != tt.wantToken {
+				t.Errorf("tokenSource.Token() = %v, want %v", got.AccessToken, tt.wantToken)
+			}
+			if fetched != tt.wantFetch {
+				t.Errorf("tokenSource.Token() fetched %d times, want %d", fetched, tt.wantFetch)
+			}
+		})
+	}
+}
+
+func TestTokenSource_Concurrent(t *testing.T) {
+	var mu sync.Mutex
+	fetched := 0
+	s := newTokenSource(func(ctx context.Context) (*Token, error) {
+		mu.Lock()
+		fetched++
+		mu.Unlock()
+		return &Token{AccessToken: "fresh", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Token(context.Background()); err != nil {
+				t.Errorf("tokenSource.Token() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if fetched != 1 {
+		t.Errorf("tokenSource.Token() fetched %d times, want 1", fetched)
+	}
+}
+
+func TestDomoAPI_do(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantStatus int
+		api        func(ctrl *gomock.Controller) *DomoAPI
+	}{
+		{
+			name:       "sends cached token",
+			wantStatus: 200,
+			api: func(ctrl *gomock.Controller) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					if got := req.Header.Get("Authorization"); got != "bearer "+sampleToken.AccessToken {
+						t.Errorf("Authorization = %v", got)
+					}
+					return getMockResponse("[]", 200), nil
+				})
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens:                &tokenSource{token: &sampleToken},
+				}
+			},
+		},
+		{
+			name:       "refresh token and retry once on 401",
+			wantStatus: 200,
+			api: func(ctrl *gomock.Controller) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				gomock.InOrder(
+					rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 401), nil),
+					rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+						if got := req.Header.Get("Authorization"); got != "bearer fresh" {
+							t.Errorf("Authorization = %v, want bearer fresh", got)
+						}
+						return getMockResponse("[]", 200), nil
+					}),
+				)
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens: &tokenSource{
+						token: &sampleToken,
+						fetch: func(ctx context.Context) (*Token, error) {
+							return &Token{AccessToken: "fresh", ExpiresAt: time.Now().Add(time.Hour)}, nil
+						},
+					},
+				}
+			},
+		},
+		{
+			name:       "give up after second 401",
+			wantStatus: 401,
+			api: func(ctrl *gomock.Controller) *DomoAPI {
+				rmock := mocks.NewMockRequestHandlerService(ctrl)
+				rmock.EXPECT().Handler(gomock.Any()).Return(getMockResponse(errorJSON, 401), nil).Times(2)
+				return &DomoAPI{
+					requestHandlerService: rmock,
+					tokens: &tokenSource{
+						token: &sampleToken,
+						fetch: func(ctx context.Context) (*Token, error) {
+							return &Token{AccessToken: "fresh", ExpiresAt: time.Now().Add(time.Hour)}, nil
+						},
+					},
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			domoAPI := tt.api(ctrl)
+
+			req, _ := http.NewRequest(http.MethodGet, "https://api.domo.example.com/v1/datasets", nil)
+			resp, err := domoAPI.do(req)
+			if err != nil {
+				t.Errorf("DomoAPI.do() error = %v", err)
+				return
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("DomoAPI.do() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package domoapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+//DefaultPartSize is the size of the csv data, before compression, of the parts made by UploadStream
+const DefaultPartSize = 32 << 20
+
+//Checkpoint records the progress of an UploadStream execution.
+//Part boundaries depend on PartSize, Digests holds the sha256 of the csv data of each uploaded part.
+type Checkpoint struct {
+	StreamID    int            `json:"streamId"`
+	ExecutionID int            `json:"executionId"`
+	PartSize    int            `json:"partSize"`
+	Parts       []int          `json:"parts"`
+	Digests     map[int]string `json:"digests,omitempty"`
+}
+
+//CheckpointStore persists upload checkpoints by key. Load returns nil without error if there is none.
+type CheckpointStore interface {
+	Load(key string) (*Checkpoint, error)
+	Save(key string, cp *Checkpoint) error
+	Delete(key string) error
+}
+
+//FileCheckpointStore keeps each checkpoint as a json file in Dir
+type FileCheckpointStore struct {
+	Dir string
+}
+
+//NewFileCheckpointStore returns a store writing checkpoints into dir, which is created when needed
+func NewFileCheckpointStore(dir string) *FileCheckpointStore {
+	return &FileCheckpointStore{Dir: dir}
+}
+
+func (s *FileCheckpointStore) path(key string) string {
+	return filepath.Join(s.Dir, url.PathEscape(key)+".json")
+}
+
+//Load reads the checkpoint of key
+func (s *FileCheckpointStore) Load(key string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("error: invalid checkpoint %s - %v", s.path(key), err)
+	}
+	return &cp, nil
+}
+
+//Save writes the checkpoint of key, replacing the previous one atomically
+func (s *FileCheckpointStore) Save(key string, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(s.Dir, ".checkpoint-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.path(key))
+}
+
+//Delete removes the checkpoint of key
+func (s *FileCheckpointStore) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+//StreamUploadOptions configures UploadStream
+type StreamUploadOptions struct {
+	//PartSize is the approximate size of the parts before compression, DefaultPartSize by default.
+	//Parts are cut between csv records.
+	PartSize int
+	//Concurrency is the number of parts uploaded at the same time, DefaultPartConcurrency by default
+	Concurrency int
+	//Checkpoints stores the upload progress, by default files in the "go-domo-api" directory of os.TempDir
+	Checkpoints CheckpointStore
+	//Key names the checkpoint of the upload, "stream-<streamID>" by default
+	Key string
+}
+
+//UploadStream loads the csv data read from r into a stream. The data is split into gzipped parts which are
+//uploaded by a pool of workers, and the execution is committed once all parts are uploaded.
+//
+//Every uploaded part is checkpointed. When an upload fails, the execution is left open and calling
+//UploadStream again with the same key, the same data and the same PartSize resumes it, skipping the parts already uploaded.
+//Resuming with another PartSize, or with data that no longer matches the uploaded parts or ends before one of them,
+//fails and keeps the checkpoint.
+//The checkpoint is removed after the commit.
+func (d *DomoAPI) UploadStream(ctx context.Context, streamID int, r io.Reader, opts StreamUploadOptions) (*Execution, error) {
+	store := opts.Checkpoints
+	if store == nil {
+		store = NewFileCheckpointStore(filepath.Join(os.TempDir(), "go-domo-api"))
+	}
+	key := opts.Key
+	if key == "" {
+		key = "stream-" + strconv.Itoa(streamID)
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultPartConcurrency
+	}
+	partSize := opts.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+
+	cp, err := d.resumeCheckpoint(ctx, store, key, streamID)
+	if err != nil {
+		return nil, err
+	}
+	if cp != nil && cp.PartSize != partSize {
+		return nil, fmt.Errorf("error: checkpoint %s was made with parts of %d bytes, cannot resume with parts of %d bytes", key, cp.PartSize, partSize)
+	}
+	if cp == nil {
+		exec, err := d.CreateExecution(ctx, streamID)
+		if err != nil {
+			return nil, err
+		}
+		cp = &Checkpoint{StreamID: streamID, ExecutionID: exec.ID, PartSize: partSize}
+		if err := store.Save(key, cp); err != nil {
+			return nil, err
+		}
+	}
+	if cp.Digests == nil {
+		cp.Digests = make(map[int]string)
+	}
+	done := make(map[int]string, len(cp.Parts))
+	lastDone := 0
+	for _, part := range cp.Parts {
+		digest, ok := cp.Digests[part]
+		if !ok {
+			return nil, fmt.Errorf("error: checkpoint %s has no digest of part %d, cannot check that the data is unchanged", key, part)
+		}
+		done[part] = digest
+		if part > lastDone {
+			lastDone = part
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type job struct {
+		part int
+		data []byte
+	}
+	jobs := make(chan job)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				data, err := gzipBytes(j.data)
+				if err == nil {
+					err = d.uploadPart(ctx, streamID, cp.ExecutionID, j.part, bytes.NewReader(data), true)
+				}
+				if err != nil {
+					fail(fmt.Errorf("error: part %d - %w", j.part, err))
+					continue
+				}
+
+				mu.Lock()
+				cp.Parts = append(cp.Parts, j.part)
+				sort.Ints(cp.Parts)
+				cp.Digests[j.part] = partDigest(j.data)
+				err = store.Save(key, cp)
+				mu.Unlock()
+				if err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	split := newPartSplitter(r, partSize)
+	for part := 1; ctx.Err() == nil; part++ {
+		data, err := split.next()
+		if err == io.EOF {
+			//parts uploaded past the end of the data would be committed with it
+			if part <= lastDone {
+				fail(fmt.Errorf("error: the data ends before part %d which was uploaded, the data changed since checkpoint %s", lastDone, key))
+			}
+			break
+		}
+		if err != nil {
+			fail(err)
+			break
+		}
+		if digest, ok := done[part]; ok {
+			if digest != partDigest(data) {
+				fail(fmt.Errorf("error: part %d differs from the uploaded one, the data changed since checkpoint %s", part, key))
+				break
+			}
+			continue
+		}
+		select {
+		case jobs <- job{part: part, data: data}:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	exec, err := d.CommitExecution(ctx, streamID, cp.ExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Delete(key); err != nil {
+		return exec, err
+	}
+	return exec, nil
+}
+
+func partDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+//resumeCheckpoint returns the checkpoint of key if its execution is still open, otherwise it drops it
+func (d *DomoAPI) resumeCheckpoint(ctx context.Context, store CheckpointStore, key string, streamID int) (*Checkpoint, error) {
+	cp, err := store.Load(key)
+	if err != nil || cp == nil {
+		return nil, err
+	}
+	if cp.StreamID == streamID {
+		exec, err := d.GetExecution(ctx, streamID, cp.ExecutionID)
+		if err != nil && !IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil && exec.CurrentState == ExecutionStateActive {
+			return cp, nil
+		}
+	}
+	return nil, store.Delete(key)
+}
+
+//partSplitter cuts csv data into parts of at least size bytes, between records
+type partSplitter struct {
+	r    *bufio.Reader
+	size int
+}
+
+func newPartSplitter(r io.Reader, size int) *partSplitter {
+	if size <= 0 {
+		size = DefaultPartSize
+	}
+	return &partSplitter{r: bufio.NewReader(r), size: size}
+}
+
+//next returns the next part, or io.EOF after the last one
+func (s *partSplitter) next() ([]byte, error) {
+	var part []byte
+	quoted := false
+	for {
+		line, err := s.r.ReadSlice('\n')
+		part = append(part, line...)
+		//a newline inside a quoted field does not end the record
+		quoted = quoted != (bytes.Count(line, []byte{'"'})%2 == 1)
+		if err == io.EOF || (err == nil && !quoted && len(part) >= s.size) {
+			if len(part) == 0 {
+				return nil, io.EOF
+			}
+			return part, nil
+		}
+		if err != nil && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+	}
+}
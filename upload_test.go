@@ -0,0 +1,302 @@
+package domoapi
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+func Test_partSplitter(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		size int
+		want []string
+	}{
+		{
+			name: "records are not cut",
+			data: "Euler,TRUE\nGauss,FALSE\nFermat,TRUE\n",
+			size: 15,
+			want: []string{"Euler,TRUE\nGauss,FALSE\n", "Fermat,TRUE\n"},
+		},
+		{
+			name: "quoted newlines stay in the record",
+			data: "\"Euler\nLeonhard\",TRUE\nGauss,FALSE\n",
+			size: 5,
+			want: []string{"\"Euler\nLeonhard\",TRUE\n", "Gauss,FALSE\n"},
+		},
+		{
+			name: "last record without newline",
+			data: "Euler,TRUE\nGauss,FALSE",
+			size: 1,
+			want: []string{"Euler,TRUE\n", "Gauss,FALSE"},
+		},
+		{
+			name: "empty",
+			size: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newPartSplitter(strings.NewReader(tt.data), tt.size)
+			var got []string
+			for {
+				part, err := s.next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("partSplitter.next() error = %v", err)
+				}
+				got = append(got, string(part))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parts = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := NewFileCheckpointStore(dir + "/nested")
+
+	if cp, err := store.Load("sales/2020"); cp != nil || err != nil {
+		t.Errorf("Load() of a missing checkpoint = %v, %v", cp, err)
+	}
+	want := &Checkpoint{StreamID: 42, ExecutionID: 7, PartSize: 20, Parts: []int{1, 3}, Digests: map[int]string{1: "a", 3: "b"}}
+	if err := store.Save("sales/2020", want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got, err := store.Load("sales/2020"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %v, %v, want %v", got, err, want)
+	}
+	if err := store.Delete("sales/2020"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err := store.Delete("sales/2020"); err != nil {
+		t.Errorf("Delete() of a missing checkpoint error = %v", err)
+	}
+	if cp, _ := store.Load("sales/2020"); cp != nil {
+		t.Errorf("Load() after Delete() = %v", cp)
+	}
+}
+
+type memoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+func (s *memoryCheckpointStore) Load(key string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.checkpoints[key]
+	if !ok {
+		return nil, nil
+	}
+	return &cp, nil
+}
+
+func (s *memoryCheckpointStore) Save(key string, cp *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *cp
+	c.Parts = append([]int(nil), cp.Parts...)
+	c.Digests = make(map[int]string, len(cp.Digests))
+	for part, digest := range cp.Digests {
+		c.Digests[part] = digest
+	}
+	s.checkpoints[key] = c
+	return nil
+}
+
+func (s *memoryCheckpointStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, key)
+	return nil
+}
+
+func TestDomoAPI_UploadStream(t *testing.T) {
+	const data = "Euler,TRUE\nGauss,FALSE\nFermat,TRUE\nNoether,TRUE\n"
+	first := map[int]string{1: partDigest([]byte("Euler,TRUE\nGauss,FALSE\n"))}
+	shorter := map[int]string{
+		1: first[1],
+		2: partDigest([]byte("Fermat,TRUE\nNoether,TRUE\n")),
+		5: partDigest([]byte("Hilbert,FALSE\n")),
+	}
+	tests := []struct {
+		name           string
+		checkpoint     *Checkpoint
+		executionState string
+		partSize       int
+		failPart       string
+		wantCalls      []string
+		wantParts      map[string]string
+		wantErr        bool
+		wantCheckpoint *Checkpoint
+	}{
+		{
+			name: "new execution",
+			wantCalls: []string{
+				"POST /v1/streams/42/executions",
+				"PUT /v1/streams/42/executions/7/commit",
+			},
+			wantParts: map[string]string{"1": "Euler,TRUE\nGauss,FALSE\n", "2": "Fermat,TRUE\nNoether,TRUE\n"},
+		},
+		{
+			name:           "resume open execution",
+			checkpoint:     &Checkpoint{StreamID: 42, ExecutionID: 5, PartSize: 20, Parts: []int{1}, Digests: first},
+			executionState: ExecutionStateActive,
+			wantCalls: []string{
+				"GET /v1/streams/42/executions/5",
+				"PUT /v1/streams/42/executions/5/commit",
+			},
+			wantParts: map[string]string{"2": "Fermat,TRUE\nNoether,TRUE\n"},
+		},
+		{
+			name:           "checkpoint of a finished execution",
+			checkpoint:     &Checkpoint{StreamID: 42, ExecutionID: 5, PartSize: 20, Parts: []int{1}, Digests: first},
+			executionState: ExecutionStateSuccess,
+			wantCalls: []string{
+				"GET /v1/streams/42/executions/5",
+				"POST /v1/streams/42/executions",
+				"PUT /v1/streams/42/executions/7/commit",
+			},
+			wantParts: map[string]string{"1": "Euler,TRUE\nGauss,FALSE\n", "2": "Fermat,TRUE\nNoether,TRUE\n"},
+		},
+		{
+			name:     "failed part keeps the execution open",
+			failPart: "2",
+			wantCalls: []string{
+				"POST /v1/streams/42/executions",
+			},
+			wantParts:      map[string]string{"1": "Euler,TRUE\nGauss,FALSE\n"},
+			wantErr:        true,
+			wantCheckpoint: &Checkpoint{StreamID: 42, ExecutionID: 7, PartSize: 20, Parts: []int{1}, Digests: first},
+		},
+		{
+			name:           "resume with another part size",
+			checkpoint:     &Checkpoint{StreamID: 42, ExecutionID: 5, PartSize: 20, Parts: []int{1}, Digests: first},
+			executionState: ExecutionStateActive,
+			partSize:       10,
+			wantCalls:      []string{"GET /v1/streams/42/executions/5"},
+			wantParts:      map[string]string{},
+			wantErr:        true,
+			wantCheckpoint: &Checkpoint{StreamID: 42, ExecutionID: 5, PartSize: 20, Parts: []int{1}, Digests: first},
+		},
+		{
+			name:           "resume with changed data",
+			checkpoint:     &Checkpoint{StreamID: 42, ExecutionID: 5, PartSize: 20, Parts: []int{1}, Digests: map[int]string{1: partDigest([]byte("Euler,FALSE\nGauss,FALSE\n"))}},
+			executionState: ExecutionStateActive,
+			wantCalls:      []string{"GET /v1/streams/42/executions/5"},
+			wantParts:      map[string]string{},
+			wantErr:        true,
+			wantCheckpoint: &Checkpoint{StreamID: 42, ExecutionID: 5, PartSize: 20, Parts: []int{1}, Digests: map[int]string{1: partDigest([]byte("Euler,FALSE\nGauss,FALSE\n"))}},
+		},
+		{
+			name:           "resume with shorter data",
+			checkpoint:     &Checkpoint{StreamID: 42, ExecutionID: 5, PartSize: 20, Parts: []int{1, 2, 5}, Digests: shorter},
+			executionState: ExecutionStateActive,
+			wantCalls:      []string{"GET /v1/streams/42/executions/5"},
+			wantParts:      map[string]string{},
+			wantErr:        true,
+			wantCheckpoint: &Checkpoint{StreamID: 42, ExecutionID: 5, PartSize: 20, Parts: []int{1, 2, 5}, Digests: shorter},
+		},
+		{
+			name:           "resume without digests",
+			checkpoint:     &Checkpoint{StreamID: 42, ExecutionID: 5, PartSize: 20, Parts: []int{1}, Digests: map[int]string{}},
+			executionState: ExecutionStateActive,
+			wantCalls:      []string{"GET /v1/streams/42/executions/5"},
+			wantParts:      map[string]string{},
+			wantErr:        true,
+			wantCheckpoint: &Checkpoint{StreamID: 42, ExecutionID: 5, PartSize: 20, Parts: []int{1}, Digests: map[int]string{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := &memoryCheckpointStore{checkpoints: map[string]Checkpoint{}}
+			if tt.checkpoint != nil {
+				_ = store.Save("stream-42", tt.checkpoint)
+			}
+
+			var mu sync.Mutex
+			var calls []string
+			parts := make(map[string]string)
+			rmock := mocks.NewMockRequestHandlerService(ctrl)
+			rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if i := strings.Index(req.URL.Path, "/part/"); i >= 0 {
+					part := req.URL.Path[i+len("/part/"):]
+					if part == tt.failPart {
+						return getMockResponse(errorJSON, 500), nil
+					}
+					zr, err := gzip.NewReader(req.Body)
+					if err != nil {
+						t.Errorf("part %s is not gzipped: %v", part, err)
+						return getMockResponse(errorJSON, 400), nil
+					}
+					b, _ := ioutil.ReadAll(zr)
+					parts[part] = string(b)
+					return getMockResponse("", 200), nil
+				}
+				calls = append(calls, req.Method+" "+req.URL.Path)
+				switch {
+				case req.Method == http.MethodPost:
+					return getMockResponse(executionJSON, 201), nil
+				case req.Method == http.MethodGet:
+					return getMockResponse(`{"id": 5, "currentState": "`+tt.executionState+`"}`, 200), nil
+				}
+				return getMockResponse(committedExecutionJSON, 200), nil
+			}).AnyTimes()
+			domoAPI := &DomoAPI{
+				requestHandlerService: rmock,
+				tokens:                &tokenSource{token: &sampleToken},
+			}
+
+			partSize := tt.partSize
+			if partSize == 0 {
+				partSize = 20
+			}
+			exec, err := domoAPI.UploadStream(context.Background(), 42, strings.NewReader(data), StreamUploadOptions{
+				PartSize:    partSize,
+				Concurrency: 1,
+				Checkpoints: store,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DomoAPI.UploadStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && exec.CurrentState != ExecutionStateSuccess {
+				t.Errorf("DomoAPI.UploadStream() = %+v", exec)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(parts, tt.wantParts) {
+				t.Errorf("uploaded parts = %q, want %q", parts, tt.wantParts)
+			}
+			got, _ := store.Load("stream-42")
+			if !reflect.DeepEqual(got, tt.wantCheckpoint) {
+				t.Errorf("checkpoint = %+v, want %+v", got, tt.wantCheckpoint)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package domoapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//User is a Domo user. Email, Name and Role are required to create or update a user.
+type User struct {
+	ID             int64       `json:"id,omitempty"`
+	Name           string      `json:"name,omitempty"`
+	Email          string      `json:"email,omitempty"`
+	AlternateEmail string      `json:"alternateEmail,omitempty"`
+	Role           string      `json:"role,omitempty"`
+	RoleID         int64       `json:"roleId,omitempty"`
+	Title          string      `json:"title,omitempty"`
+	Phone          string      `json:"phone,omitempty"`
+	Location       string      `json:"location,omitempty"`
+	EmployeeNumber string      `json:"employeeNumber,omitempty"`
+	Timezone       string      `json:"timezone,omitempty"`
+	Locale         string      `json:"locale,omitempty"`
+	Image          string      `json:"image,omitempty"`
+	Groups         []UserGroup `json:"groups,omitempty"`
+	CreatedAt      *time.Time  `json:"createdAt,omitempty"`
+	UpdatedAt      *time.Time  `json:"updatedAt,omitempty"`
+}
+
+//UserGroup is a group a user belongs to
+type UserGroup struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+//Default user roles
+const (
+	UserRoleAdmin       = "Admin"
+	UserRolePrivileged  = "Privileged"
+	UserRoleEditor      = "Editor"
+	UserRoleParticipant = "Participant"
+	UserRoleSocial      = "Social"
+)
+
+//maxUserPageSize is the largest number of users Domo returns per page
+const maxUserPageSize = 500
+
+func userPath(userID int64) string {
+	return "/v1/users/" + strconv.FormatInt(userID, 10)
+}
+
+//ListUsers lists all users of the domo instance
+func (d *DomoAPI) ListUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	for offset := 0; ; offset += maxUserPageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := d.ListUsersPage(ctx, maxUserPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, page...)
+		if len(page) < maxUserPageSize {
+			return users, nil
+		}
+	}
+}
+
+//ListUsersPage lists a page of users, limit is at most 500
+func (d *DomoAPI) ListUsersPage(ctx context.Context, limit, offset int) ([]User, error) {
+	path := fmt.Sprintf("/v1/users?limit=%d&offset=%d", limit, offset)
+	req, err := d.newRequest(ctx, http.MethodGet, path, nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var users []User
+	if err := d.doJSON(req, &users, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+//GetUser gets a user by ID
+func (d *DomoAPI) GetUser(ctx context.Context, userID int64) (*User, error) {
+	req, err := d.newRequest(ctx, http.MethodGet, userPath(userID), nil, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var u *User
+	if err := d.doJSON(req, &u, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+//CreateUser creates a user, sendInvite emails the user an invitation to Domo
+func (d *DomoAPI) CreateUser(ctx context.Context, user User, sendInvite bool) (*User, error) {
+	if user.Email == "" || user.Name == "" || user.Role == "" {
+		return nil, fmt.Errorf("error: users need an email, a name and a role")
+	}
+	user.ID = 0
+	path := "/v1/users?sendInvite=" + strconv.FormatBool(sendInvite)
+	req, err := d.newJSONRequest(WithIdempotent(ctx, false), http.MethodPost, path, user)
+	if err != nil {
+		return nil, err
+	}
+
+	var u *User
+	if err := d.doJSON(req, &u, http.StatusOK, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+//UpdateUser updates a user and returns the updated user
+func (d *DomoAPI) UpdateUser(ctx context.Context, userID int64, user User) (*User, error) {
+	if user.Email == "" || user.Name == "" || user.Role == "" {
+		return nil, fmt.Errorf("error: users need an email, a name and a role")
+	}
+	user.ID = userID
+	req, err := d.newJSONRequest(ctx, http.MethodPut, userPath(userID), user)
+	if err != nil {
+		return nil, err
+	}
+
+	var u *User
+	if err := d.doJSON(req, &u, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+//DeleteUser permanently deletes a user
+func (d *DomoAPI) DeleteUser(ctx context.Context, userID int64) error {
+	req, err := d.newRequest(ctx, http.MethodDelete, userPath(userID), nil, "")
+	if err != nil {
+		return err
+	}
+	return d.doJSON(req, nil, http.StatusNoContent)
+}
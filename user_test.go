@@ -0,0 +1,175 @@
+package domoapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mocks "github.com/rakutentech/go-domo-api/mocks"
+)
+
+const userJSON = `{
+	"id": 27,
+	"title": "Mathematician",
+	"email": "leonhard.euler@domo.com",
+	"role": "Admin",
+	"phone": "+41 11-111-1111",
+	"name": "Leonhard Euler",
+	"location": "Basel",
+	"timezone": "Europe/Zurich",
+	"groups": [ { "id": 3, "name": "Mathematicians" } ],
+	"createdAt": "2016-06-21T17:20:36Z",
+	"updatedAt": "2016-06-21T17:20:36Z"
+}`
+
+func TestDomoAPI_userMethods(t *testing.T) {
+	euler := User{Name: "Leonhard Euler", Email: "leonhard.euler@domo.com", Role: UserRoleAdmin}
+	checkEuler := func(t *testing.T, u *User) {
+		t.Helper()
+		if u.ID != 27 || u.Name != "Leonhard Euler" || u.Role != UserRoleAdmin || len(u.Groups) != 1 || u.Groups[0].ID != 3 ||
+			u.CreatedAt == nil || !u.CreatedAt.Equal(time.Date(2016, 6, 21, 17, 20, 36, 0, time.UTC)) {
+			t.Errorf("user = %+v", u)
+		}
+	}
+	tests := []struct {
+		name    string
+		request mockedRequest
+		run     func(t *testing.T, d *DomoAPI)
+	}{
+		{
+			name:    "list page",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/users?limit=50&offset=100", response: "[" + userJSON + "]", statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.ListUsersPage(context.Background(), 50, 100)
+				if err != nil {
+					t.Fatalf("DomoAPI.ListUsersPage() error = %v", err)
+				}
+				if len(got) != 1 {
+					t.Fatalf("DomoAPI.ListUsersPage() returned %d users", len(got))
+				}
+				checkEuler(t, &got[0])
+			},
+		},
+		{
+			name:    "get",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/users/27", response: userJSON, statusCode: 200},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.GetUser(context.Background(), 27)
+				if err != nil {
+					t.Fatalf("DomoAPI.GetUser() error = %v", err)
+				}
+				checkEuler(t, got)
+			},
+		},
+		{
+			name:    "get not found",
+			request: mockedRequest{method: http.MethodGet, uri: "/v1/users/28", response: notFoundJSON, statusCode: 404},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.GetUser(context.Background(), 28)
+				if !IsNotFound(err) || got != nil {
+					t.Errorf("DomoAPI.GetUser() = %v, %v, want not found", got, err)
+				}
+			},
+		},
+		{
+			name: "create with invite",
+			request: mockedRequest{
+				method:     http.MethodPost,
+				uri:        "/v1/users?sendInvite=true",
+				body:       `{"name":"Leonhard Euler","email":"leonhard.euler@domo.com","role":"Admin"}`,
+				response:   userJSON,
+				statusCode: 200,
+			},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.CreateUser(context.Background(), euler, true)
+				if err != nil {
+					t.Fatalf("DomoAPI.CreateUser() error = %v", err)
+				}
+				checkEuler(t, got)
+			},
+		},
+		{
+			name: "update",
+			request: mockedRequest{
+				method:     http.MethodPut,
+				uri:        "/v1/users/27",
+				body:       `{"id":27,"name":"Leonhard Euler","email":"leonhard.euler@domo.com","role":"Admin"}`,
+				response:   userJSON,
+				statusCode: 200,
+			},
+			run: func(t *testing.T, d *DomoAPI) {
+				got, err := d.UpdateUser(context.Background(), 27, euler)
+				if err != nil {
+					t.Fatalf("DomoAPI.UpdateUser() error = %v", err)
+				}
+				checkEuler(t, got)
+			},
+		},
+		{
+			name:    "delete",
+			request: mockedRequest{method: http.MethodDelete, uri: "/v1/users/27", statusCode: 204},
+			run: func(t *testing.T, d *DomoAPI) {
+				if err := d.DeleteUser(context.Background(), 27); err != nil {
+					t.Errorf("DomoAPI.DeleteUser() error = %v", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tt.run(t, newMockedDomoAPI(t, ctrl, tt.request))
+		})
+	}
+}
+
+func TestDomoAPI_CreateUser_missingFields(t *testing.T) {
+	domoAPI := &DomoAPI{tokens: &tokenSource{token: &sampleToken}}
+	if _, err := domoAPI.CreateUser(context.Background(), User{Name: "Leonhard Euler"}, false); err == nil {
+		t.Errorf("DomoAPI.CreateUser() without email and role should fail")
+	}
+}
+
+func TestDomoAPI_ListUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	//a full page then a short one
+	var full []User
+	for i := 0; i < maxUserPageSize; i++ {
+		full = append(full, User{ID: int64(i + 1), Name: fmt.Sprintf("user %d", i+1)})
+	}
+	fullJSON, _ := json.Marshal(full)
+
+	rmock := mocks.NewMockRequestHandlerService(ctrl)
+	first := rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		if req.URL.RequestURI() != "/v1/users?limit=500&offset=0" {
+			t.Errorf("request = %v", req.URL)
+		}
+		return getMockResponse(string(fullJSON), 200), nil
+	})
+	rmock.EXPECT().Handler(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		if req.URL.RequestURI() != "/v1/users?limit=500&offset=500" {
+			t.Errorf("request = %v", req.URL)
+		}
+		return getMockResponse("["+userJSON+"]", 200), nil
+	}).After(first)
+	domoAPI := &DomoAPI{
+		requestHandlerService: rmock,
+		tokens:                &tokenSource{token: &sampleToken},
+	}
+
+	got, err := domoAPI.ListUsers(context.Background())
+	if err != nil {
+		t.Fatalf("DomoAPI.ListUsers() error = %v", err)
+	}
+	if len(got) != maxUserPageSize+1 || got[maxUserPageSize].Name != "Leonhard Euler" {
+		t.Errorf("DomoAPI.ListUsers() returned %d users", len(got))
+	}
+}